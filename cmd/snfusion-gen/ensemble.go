@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/astrogo/snfusion/sim"
+)
+
+// runEnsemble implements the ensemble sub-command, computing the yields of
+// an ensemble of simulations with uncertain cross-sections.
+func runEnsemble(args []string) {
+	fset := flag.NewFlagSet("ensemble", flag.ExitOnError)
+	nCarbons := fset.Float64("carbon-ratio", 60, "carbon ratio (0-100) giving the initial Carbon/Oxygen composition")
+	nIters := fset.Int("n", 100000, "number of iterations to simulate")
+	seed := fset.Int64("seed", 1234, "seed of the first simulation of the ensemble")
+	members := fset.Int("members", 10, "number of simulations")
+	xsErr := fset.Float64("xsect-err", 0, "relative uncertainty of the cross-sections (0: exact cross-sections)")
+	xsDist := fset.String("xsect-dist", "lognormal", "distribution of the cross-sections (gaussian, lognormal)")
+	oname := fset.String("o", "", "output file name (default: standard output)")
+	fset.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of snfusion-gen ensemble:\n")
+		fset.PrintDefaults()
+	}
+	fset.Parse(args)
+
+	dist, err := sim.ParseDistribution(*xsDist)
+	if err != nil {
+		log.Fatalf("invalid -xsect-dist value: %v\n", err)
+	}
+
+	ens := sim.Ensemble{
+		Engine: sim.Engine{
+			NumIters:   *nIters,
+			NumCarbons: *nCarbons,
+			Seed:       *seed,
+		},
+		Members: *members,
+	}
+	if *xsErr > 0 {
+		ens.Table = sim.NewTable(*xsErr, dist)
+	}
+
+	yields, err := ens.Run()
+	if err != nil {
+		log.Fatalf("error running ensemble: %v\n", err)
+	}
+
+	o := []string{fmt.Sprintf("yields of %d simulations (mass):", ens.Members)}
+	for _, y := range yields {
+		o = append(o, fmt.Sprintf("%v: %.6g ± %.3g", y.Nucleus, y.Mean, y.Std))
+	}
+	out := strings.Join(o, "\n") + "\n"
+
+	if *oname == "" {
+		fmt.Print(out)
+		return
+	}
+
+	f, err := os.Create(*oname)
+	if err != nil {
+		log.Fatalf("error creating %s: %v\n", *oname, err)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(out)
+	if err != nil {
+		log.Fatalf("error writing yields: %v\n", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		log.Fatalf("error closing %s: %v\n", *oname, err)
+	}
+}
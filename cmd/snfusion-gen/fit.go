@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/astrogo/snfusion/sim"
+)
+
+// runFit implements the fit sub-command, inferring the initial carbon
+// ratio that best reproduces observed yields.
+func runFit(args []string) {
+	fset := flag.NewFlagSet("fit", flag.ExitOnError)
+	yname := fset.String("yields", "", "file of observed yields, one 'nucleus yield uncertainty' line per nucleus")
+	nIters := fset.String("n", "100000", "comma separated list of numbers of iterations to fit")
+	seed := fset.Int64("seed", 1234, "seed of the first simulation of each ensemble")
+	members := fset.Int("members", 10, "number of simulations per grid point")
+	cmin := fset.Float64("carbon-min", 0, "minimal carbon ratio of the grid")
+	cmax := fset.Float64("carbon-max", 100, "maximal carbon ratio of the grid")
+	cstep := fset.Float64("carbon-step", 5, "carbon ratio step of the grid")
+	fset.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of snfusion-gen fit:\n")
+		fset.PrintDefaults()
+	}
+	fset.Parse(args)
+
+	if *yname == "" {
+		log.Fatalf("missing -yields file\n")
+	}
+	if *cstep <= 0 || *cmax < *cmin {
+		log.Fatalf("invalid carbon ratio grid\n")
+	}
+
+	f, err := os.Open(*yname)
+	if err != nil {
+		log.Fatalf("error opening %s: %v\n", *yname, err)
+	}
+	obs, err := sim.ReadObservations(f)
+	f.Close()
+	if err != nil {
+		log.Fatalf("error reading observed yields: %v\n", err)
+	}
+
+	var iters []int
+	for _, tok := range strings.Split(*nIters, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(tok))
+		if err != nil {
+			log.Fatalf("invalid -n value: %v\n", err)
+		}
+		iters = append(iters, n)
+	}
+
+	var carbons []float64
+	for c := *cmin; c <= *cmax+1e-9; c += *cstep {
+		carbons = append(carbons, c)
+	}
+
+	fit := sim.Fit{
+		Engine: sim.Engine{
+			NumIters: iters[0],
+			Seed:     *seed,
+		},
+		Members:      *members,
+		Observations: obs,
+		Carbons:      carbons,
+		Iters:        iters,
+	}
+
+	log.Printf("fitting %d grid points...\n", len(carbons)*len(iters))
+	res, err := fit.Run()
+	if err != nil {
+		log.Fatalf("error fitting yields: %v\n", err)
+	}
+
+	fmt.Printf("# carbon-ratio iterations chi2\n")
+	for _, pt := range res.Points {
+		fmt.Printf("%g %d %g\n", pt.NumCarbons, pt.NumIters, pt.Chi2)
+	}
+	fmt.Printf("best fit: carbon-ratio=%g iterations=%d chi2/ndf=%g/%d\n",
+		res.Best.NumCarbons, res.Best.NumIters, res.Best.Chi2, res.NDF,
+	)
+	fmt.Printf("carbon-ratio 68%% confidence interval: [%g, %g]\n",
+		res.CarbonsLo, res.CarbonsHi,
+	)
+}
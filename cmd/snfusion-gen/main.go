@@ -4,13 +4,21 @@ package main
 
 import (
 	"bufio"
+	"compress/gzip"
 	"flag"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"runtime/pprof"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/astrogo/snfusion/sim"
+	"github.com/astrogo/snfusion/sim/reaclib"
+	"github.com/astrogo/snfusion/sim/snio"
 )
 
 var (
@@ -26,13 +34,132 @@ var (
 		"seed", 1234,
 		"seed used for the MonteCarlo",
 	)
+	model = flag.String(
+		"model", sim.DefaultModel,
+		"fusion model ("+strings.Join(sim.Models(), ", ")+")",
+	)
+	maxA = flag.Int(
+		"max-a", sim.DefaultChart.MaxA,
+		"maximum mass number of fusion products (0: no limit)",
+	)
+	maxZ = flag.Int(
+		"max-z", sim.DefaultChart.MaxZ,
+		"maximum atomic number of fusion products (0: no limit)",
+	)
+	minNZ = flag.Float64(
+		"min-nz", sim.DefaultChart.MinNZ,
+		"minimum N/Z ratio of fusion products (0: no limit)",
+	)
+	maxNZ = flag.Float64(
+		"max-nz", sim.DefaultChart.MaxNZ,
+		"maximum N/Z ratio of fusion products (0: no limit)",
+	)
+	fallback = flag.String(
+		"fallback", "none",
+		"fusion probability of pairs without cross-section (none, coulomb, strict)",
+	)
+	fbNorm = flag.Float64(
+		"fallback-norm", 1,
+		"probability of the 12C+12C reference channel for the coulomb fallback",
+	)
+	fbSlope = flag.Float64(
+		"fallback-slope", 1,
+		"steepness of the Coulomb barrier suppression for the coulomb fallback",
+	)
+	check = flag.Int(
+		"check", 0,
+		"check mass and charge conservation every n iterations (0: never)",
+	)
+	rejFree = flag.Bool(
+		"rejection-free", false,
+		"use rejection-free kinetic Monte Carlo pair selection",
+	)
+	accept = flag.Bool(
+		"acceptance", false,
+		"write acceptance statistics columns",
+	)
+	accEvery = flag.Int(
+		"accrete-every", 0,
+		"inject nuclei every n iterations, with the initial C/O composition (0: never)",
+	)
+	accN = flag.Int(
+		"accrete-n", 100,
+		"number of nuclei injected per accretion period",
+	)
+	ejEvery = flag.Int(
+		"eject-every", 0,
+		"remove nuclei every n iterations (0: never)",
+	)
+	ejFrac = flag.Float64(
+		"eject-fraction", 0.01,
+		"fraction of the population removed per ejection period",
+	)
+	ejBias = flag.String(
+		"eject-bias", "",
+		"relative ejection weights of species (e.g. 56Ni:2,12C:0.5)",
+	)
+	t9 = flag.Float64(
+		"t9", 0,
+		"initial temperature in GK (0: temperature independent fusion probabilities)",
+	)
+	heatCap = flag.Float64(
+		"heat-capacity", 0,
+		"heat capacity in MeV/GK driving self-heating, with a positive -t9 (0: no self-heating)",
+	)
+	cooling = flag.Float64(
+		"cooling", 0,
+		"relaxation rate of the temperature towards its initial value, per iteration",
+	)
+	refT9 = flag.Float64(
+		"ref-t9", 0,
+		"temperature in GK of the tabulated cross-sections (0: initial temperature)",
+	)
+
+	rho = flag.Float64(
+		"rho", 0,
+		"density in g/cm^3",
+	)
+	capRate = flag.Float64(
+		"capture-rate", 0,
+		"electron capture rate normalization, per iteration (0: no electron capture)",
+	)
+	reaclibFile = flag.String(
+		"reaclib", "",
+		"REACLIB file with the reaction rates of the fusion model, at the -t9 and -rho conditions (default: use -model)",
+	)
+	popul = flag.String(
+		"population", "",
+		"comma separated list of nuclei written to the output (e.g. 12C,16O,54Fe,56Ni) (default: alpha-chain nuclei)",
+	)
 
 	doprof = flag.Bool("cpu-prof", false, "enable CPU profiling")
 
-	fname = flag.String("o", "output.csv", "output file name")
+	fname = flag.String("o", "output.csv", "output file name, in FITS, NumPy .npy or .npz, or snfusion binary format if it ends with .fits, .npy, .npz or .snb, in CSV otherwise")
+	gz    = flag.Bool("gzip", false, "compress the output file with gzip (default: if the output file name ends with .gz)")
+	evts  = flag.String("events", "", "event log file name (default: no event log)")
+	lname = flag.String("lineage", "", "lineage file name, in DOT if it ends with .dot, in JSON otherwise (default: no lineage)")
+	nname = flag.String("network", "", "reaction network DOT file name (default: no reaction network)")
+	nwgt  = flag.String("network-weight", "probability", "weight of the reaction network edges (probability, flux)")
 )
 
 func main() {
+	log.SetFlags(0)
+	log.SetPrefix("snfusion-gen: ")
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "nse":
+			runNSE(os.Args[2:])
+			return
+		case "ensemble":
+			runEnsemble(os.Args[2:])
+			return
+		case "fit":
+			runFit(os.Args[2:])
+			return
+		}
+	}
+
 	flag.Parse()
 
 	if *doprof {
@@ -45,8 +172,21 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
-	log.SetFlags(0)
-	log.SetPrefix("snfusion-gen: ")
+	fbMode, err := sim.ParseFallbackMode(*fallback)
+	if err != nil {
+		log.Fatalf("invalid -fallback value: %v\n", err)
+	}
+
+	bias, err := parseAbundances(*ejBias)
+	if err != nil {
+		log.Fatalf("invalid -eject-bias value: %v\n", err)
+	}
+
+	population, err := parseNuclei(*popul)
+	if err != nil {
+		log.Fatalf("invalid -population value: %v\n", err)
+	}
+
 	log.Printf("processing...\n")
 	beg := time.Now()
 
@@ -59,17 +199,227 @@ func main() {
 	w := bufio.NewWriter(f)
 	defer w.Flush()
 
+	var (
+		out io.Writer = w
+		zw  *gzip.Writer
+	)
+	if *gz || filepath.Ext(*fname) == ".gz" {
+		zw = gzip.NewWriter(w)
+		zw.Name = filepath.Base(strings.TrimSuffix(*fname, ".gz"))
+		zw.ModTime = beg
+		out = zw
+	}
+
 	engine := sim.Engine{
 		NumIters:   *nIters,
 		NumCarbons: *nCarbons,
 		Seed:       *seed,
+		Model:      *model,
+		Population: population,
+		Chart: &sim.Chart{
+			MaxA:  *maxA,
+			MaxZ:  *maxZ,
+			MinNZ: *minNZ,
+			MaxNZ: *maxNZ,
+		},
+		Fallback: sim.Fallback{
+			Mode:  fbMode,
+			Norm:  *fbNorm,
+			Slope: *fbSlope,
+		},
+		CheckEvery:    *check,
+		RejectionFree: *rejFree,
+		Acceptance:    *accept,
+		TrackLineage:  *lname != "",
+		Conditions: sim.Conditions{
+			T9:  *t9,
+			Rho: *rho,
+		},
+		Heating: sim.Heating{
+			HeatCapacity: *heatCap,
+			Cooling:      *cooling,
+			RefT9:        *refT9,
+		},
+		Capture: sim.Capture{
+			Rate: *capRate,
+		},
+	}
+
+	if *accEvery > 0 {
+		engine.Sources = append(engine.Sources, sim.Source{
+			Every: *accEvery,
+			N:     *accN,
+		})
+	}
+
+	if *ejEvery > 0 {
+		engine.Sinks = append(engine.Sinks, sim.Sink{
+			Every:    *ejEvery,
+			Fraction: *ejFrac,
+			Bias:     bias,
+		})
+	}
+
+	switch *nwgt {
+	case "probability", "flux":
+	default:
+		log.Fatalf("invalid -network-weight value %q\n", *nwgt)
+	}
+
+	if *reaclibFile != "" {
+		engine.Model = reaclib.ModelName
+		engine.ModelFile = *reaclibFile
+	}
+
+	if *evts != "" {
+		f, err := os.Create(*evts)
+		if err != nil {
+			log.Fatalf("error creating %s: %v\n", *evts, err)
+		}
+		defer f.Close()
+
+		w := bufio.NewWriter(f)
+		defer w.Flush()
+
+		engine.SetEventLog(w)
 	}
 
-	err = engine.Run(w)
+	err = engine.RunOutput(newOutput(strings.TrimSuffix(*fname, ".gz"), out))
 	delta := time.Now().Sub(beg)
 	log.Printf("processing... [done]: %v\n", delta)
 
 	if err != nil {
 		log.Fatalf("error running engine: %v\n", err)
 	}
+
+	if zw != nil {
+		err = zw.Close()
+		if err != nil {
+			log.Fatalf("error compressing %s: %v\n", *fname, err)
+		}
+	}
+
+	if *lname != "" {
+		err = writeLineage(*lname, engine.Lineage())
+		if err != nil {
+			log.Fatalf("error writing lineage: %v\n", err)
+		}
+	}
+
+	if *nname != "" {
+		fmodel, err := sim.NewModel(engine.Model, &engine)
+		if err != nil {
+			log.Fatalf("error creating fusion model: %v\n", err)
+		}
+		err = writeNetwork(*nname, *nwgt, fmodel, &engine)
+		if err != nil {
+			log.Fatalf("error writing reaction network: %v\n", err)
+		}
+	}
+}
+
+// newOutput returns the output writing to w the simulation,
+// in the format given by the extension of fname.
+func newOutput(fname string, w io.Writer) sim.Output {
+	switch filepath.Ext(fname) {
+	case ".fits", ".fit", ".fts":
+		return snio.NewFITSWriter(w)
+	case ".npy":
+		return snio.NewNPYWriter(w)
+	case ".npz":
+		return snio.NewNPZWriter(w)
+	case ".snb":
+		return snio.NewBinaryWriter(w)
+	default:
+		return sim.NewCSVWriter(w)
+	}
+}
+
+func writeNetwork(fname, weight string, m sim.FusionModel, engine *sim.Engine) error {
+	var (
+		net *sim.Network
+		err error
+	)
+	switch weight {
+	case "flux":
+		net = sim.NewFluxNetwork(m, engine.Flux())
+	default:
+		net, err = sim.NewNetwork(m, engine.InitialNuclei(), engine.Conditions)
+		if err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = net.WriteDOT(f)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func writeLineage(fname string, lineage *sim.Lineage) error {
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch filepath.Ext(fname) {
+	case ".dot":
+		err = lineage.WriteDOT(f)
+	default:
+		err = lineage.WriteJSON(f)
+	}
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// parseAbundances parses a comma separated list of name:weight values.
+func parseAbundances(s string) ([]sim.Abundance, error) {
+	var abs []sim.Abundance
+	if s == "" {
+		return abs, nil
+	}
+	for _, tok := range strings.Split(s, ",") {
+		i := strings.Index(tok, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("missing weight for %q", tok)
+		}
+		n, err := sim.ParseNucleus(tok[:i])
+		if err != nil {
+			return nil, err
+		}
+		w, err := strconv.ParseFloat(tok[i+1:], 64)
+		if err != nil {
+			return nil, err
+		}
+		abs = append(abs, sim.Abundance{Nucleus: n, Weight: w})
+	}
+	return abs, nil
+}
+
+// parseNuclei parses a comma separated list of nuclei.
+func parseNuclei(s string) ([]sim.Nucleus, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var nuclei []sim.Nucleus
+	for _, tok := range strings.Split(s, ",") {
+		n, err := sim.ParseNucleus(strings.TrimSpace(tok))
+		if err != nil {
+			return nil, err
+		}
+		nuclei = append(nuclei, n)
+	}
+	return nuclei, nil
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/astrogo/snfusion/sim"
+)
+
+// runNSE implements the nse sub-command, computing the composition of
+// matter in nuclear statistical equilibrium.
+func runNSE(args []string) {
+	fset := flag.NewFlagSet("nse", flag.ExitOnError)
+	t9 := fset.Float64("t9", 5, "temperature in GK")
+	rho := fset.Float64("rho", 1e8, "density in g/cm^3")
+	ye := fset.Float64("ye", 0.5, "electron fraction")
+	oname := fset.String("o", "", "output file name (default: standard output)")
+	fset.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of snfusion-gen nse:\n")
+		fset.PrintDefaults()
+	}
+	fset.Parse(args)
+
+	nse := sim.NSE{
+		T9:  *t9,
+		Rho: *rho,
+		Ye:  *ye,
+	}
+	comp, err := nse.Solve()
+	if err != nil {
+		log.Fatalf("error solving NSE: %v\n", err)
+	}
+
+	if *oname == "" {
+		fmt.Printf("%v\n", comp)
+		return
+	}
+
+	f, err := os.Create(*oname)
+	if err != nil {
+		log.Fatalf("error creating %s: %v\n", *oname, err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%v\n", comp)
+	if err != nil {
+		log.Fatalf("error writing NSE composition: %v\n", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		log.Fatalf("error closing %s: %v\n", *oname, err)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/astrogo/snfusion/sim"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// fluxGrid is the matrix of fusions between all pairs of nuclei
+// of a population.
+type fluxGrid struct {
+	data [][]int
+}
+
+func (g fluxGrid) Dims() (c, r int)   { return len(g.data), len(g.data) }
+func (g fluxGrid) Z(c, r int) float64 { return float64(g.data[r][c]) }
+func (g fluxGrid) X(c int) float64    { return float64(c) }
+func (g fluxGrid) Y(r int) float64    { return float64(r) }
+
+// plotFlux saves a heatmap of the fusions between all pairs of nuclei
+// of the engine population into the file named fname.
+func plotFlux(engine sim.Engine, flux sim.ReactionFlux, fname string) error {
+	_, fusions := flux.Matrix(engine.Population)
+
+	p, err := plot.New()
+	if err != nil {
+		return err
+	}
+
+	p.Title.Text = fmt.Sprintf(
+		"Fusions per pair of nuclei C%v-O%v (seed=%d)",
+		engine.NumCarbons,
+		100-engine.NumCarbons,
+		engine.Seed,
+	)
+
+	ticks := make([]plot.Tick, len(engine.Population))
+	for i, n := range engine.Population {
+		ticks[i] = plot.Tick{Value: float64(i), Label: label(n)}
+	}
+	p.X.Tick.Marker = plot.ConstantTicks(ticks)
+	p.Y.Tick.Marker = plot.ConstantTicks(ticks)
+
+	p.Add(plotter.NewHeatMap(fluxGrid{fusions}, palette.Heat(64, 1)))
+
+	figX := 25 * vg.Centimeter
+	return p.Save(figX, figX, fname)
+}
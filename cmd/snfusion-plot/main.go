@@ -3,20 +3,16 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
-	"encoding/csv"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"image/color"
 	"io"
 	"log"
 	"math"
-	"os"
-	"strconv"
 
+	"github.com/astrogo/snfusion/internal/snplot"
 	"github.com/astrogo/snfusion/sim"
+	"github.com/astrogo/snfusion/sim/snio"
 
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
@@ -27,92 +23,65 @@ import (
 var phi = (1 + math.Sqrt(5)) / 2
 
 func main() {
-	ifname := flag.String("f", "output.csv", "input CSV file to analyze")
+	ifname := flag.String("f", "output.csv", "input CSV or FITS file to analyze")
 	ofname := flag.String("o", "output.png", "output PNG file")
+	accept := flag.String("acceptance", "", "output PNG file for the burning efficiency plot (default: none)")
+	flux := flag.String("flux", "", "output PNG file for the reaction flux heatmap (default: none)")
 
 	flag.Parse()
 
 	log.SetPrefix("snfusion-plot: ")
 	log.SetFlags(0)
 
-	f, err := os.Open(*ifname)
+	f, err := snio.Open(*ifname)
 	if err != nil {
 		log.Fatalf("error opening %s: %v\n", *ifname, err)
 	}
 	defer f.Close()
 
-	var engine sim.Engine
-	var hdr []byte
-	{
-		scanner := bufio.NewScanner(f)
-		for scanner.Scan() {
-			data := scanner.Bytes()
-			if !bytes.HasPrefix(data, []byte("#")) {
-				break
-			}
-			if !bytes.HasPrefix(data, sim.HeaderCSV) {
-				continue
-			}
-			hdr = make([]byte, len(data)-len(sim.HeaderCSV))
-			copy(hdr, data[len(sim.HeaderCSV):])
-			break
-		}
-		err = scanner.Err()
-		if err == io.EOF {
-			err = nil
-		}
-		if err != nil {
-			log.Fatalf("error peeking at meta-data: %v\n", err)
-		}
-		if len(hdr) == 0 {
-			log.Fatalf("could not find meta-data in file %s\n",
-				*ifname,
-			)
-		}
-		_, err = f.Seek(0, 0)
-		if err != nil {
-			log.Fatalf("error rewinding input file %s: %v\n",
-				*ifname,
-				err,
-			)
-		}
-	}
-	err = json.Unmarshal(hdr, &engine)
-	if err != nil {
-		log.Fatalf("error reading meta-data: %v\n", err)
-	}
+	engine := f.Engine
 
 	log.Printf("plotting...\n")
+	log.Printf("Format:     %d\n", f.Meta.Format)
 	log.Printf("NumIters:   %d\n", engine.NumIters)
 	log.Printf("NumCarbons: %v\n", engine.NumCarbons)
 	log.Printf("Seed:       %d\n", engine.Seed)
+	log.Printf("Model:      %s\n", engine.Model)
+	chart := sim.DefaultChart
+	if engine.Chart != nil {
+		chart = *engine.Chart
+	}
+	log.Printf("Chart:      %+v\n", chart)
 	log.Printf("Nuclei:     %v\n", engine.Population)
 
-	r := csv.NewReader(f)
-	r.Comma = ';'
-	r.Comment = '#'
-
 	table := make([]plotter.XYs, len(engine.Population))
 	for i := range table {
-		table[i] = make(plotter.XYs, engine.NumIters+1)
+		table[i] = make(plotter.XYs, 0, engine.NumIters+1)
 	}
 
-	for ix := 0; ix < engine.NumIters+1; ix++ {
-		var text []string
-		text, err = r.Read()
-		if err != nil {
+	var acc []plotter.XYs
+	if engine.Acceptance {
+		acc = make([]plotter.XYs, len(sim.AcceptanceColumns))
+		for i := range acc {
+			acc[i] = make(plotter.XYs, 0, engine.NumIters+1)
+		}
+	}
+
+	for {
+		rec, err := f.Read()
+		if err == io.EOF {
 			break
 		}
-		for i := range engine.Population {
-			table[i][ix].X = float64(ix)
-			table[i][ix].Y = float64(atoi(text[i]))
+		if err != nil {
+			log.Fatalf("error reading data: %v\n", err)
+		}
+		x := float64(rec.Iter)
+		for i, m := range rec.Masses {
+			table[i] = append(table[i], plotter.XY{X: x, Y: float64(m)})
+		}
+		for i, v := range rec.Acceptance {
+			acc[i] = append(acc[i], plotter.XY{X: x, Y: float64(v)})
 		}
-	}
-	if err == io.EOF {
-		err = nil
-	}
-	if err != nil {
-		log.Fatalf("error reading data: %v\n", err)
 	}
 
 	p, err := plot.New()
@@ -155,14 +124,32 @@ func main() {
 	if err := p.Save(figX, figY, *ofname); err != nil {
 		panic(err)
 	}
-}
 
-func atoi(s string) int {
-	v, err := strconv.Atoi(s)
-	if err != nil {
-		panic(err)
+	if *accept != "" {
+		if acc == nil {
+			log.Fatalf("no acceptance statistics in file %s\n", *ifname)
+		}
+		p, err := snplot.Acceptance(engine, acc)
+		if err != nil {
+			log.Fatalf("error plotting acceptance statistics: %v\n", err)
+		}
+		err = p.Save(figX, figY, *accept)
+		if err != nil {
+			log.Fatalf("error saving acceptance statistics plot: %v\n", err)
+		}
+	}
+
+	if *flux != "" {
+		rf, err := f.Flux()
+		if err != nil {
+			log.Fatalf("error reading reaction flux: %v\n", err)
+		}
+
+		err = plotFlux(engine, rf, *flux)
+		if err != nil {
+			log.Fatalf("error plotting reaction flux: %v\n", err)
+		}
 	}
-	return v
 }
 
 func label(n sim.Nucleus) string {
@@ -190,7 +177,7 @@ func label(n sim.Nucleus) string {
 	case sim.Nucleus{A: 56, Z: 28}:
 		return "56-Ni"
 	}
-	return n.String()
+	return fmt.Sprintf("%d-%s", n.A, n.Symbol())
 }
 
 func rgb(r, g, b uint8) color.RGBA {
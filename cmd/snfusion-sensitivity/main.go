@@ -0,0 +1,154 @@
+// snfusion-sensitivity computes the sensitivity of the final yields of
+// snfusion simulations to the cross-sections of the standard fusion model.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	"github.com/astrogo/snfusion/sim"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette/moreland"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+func main() {
+	nCarbons := flag.Float64("carbon-ratio", 60, "carbon ratio (0-100) giving the initial Carbon/Oxygen composition")
+	nIters := flag.Int("n", 100000, "number of iterations to simulate")
+	seed := flag.Int64("seed", 1234, "seed of the first simulation of each ensemble")
+	members := flag.Int("members", 10, "number of simulations per perturbed cross-section")
+	delta := flag.Float64("delta", 0.1, "relative perturbation of the cross-sections")
+	oname := flag.String("o", "", "output file for the table of sensitivity coefficients (default: standard output)")
+	pname := flag.String("plot", "", "output PNG file for the sensitivity coefficients plot (default: none)")
+
+	flag.Parse()
+
+	log.SetFlags(0)
+	log.SetPrefix("snfusion-sensitivity: ")
+
+	sa := sim.SensitivityAnalysis{
+		Engine: sim.Engine{
+			NumIters:   *nIters,
+			NumCarbons: *nCarbons,
+			Seed:       *seed,
+		},
+		Delta:   *delta,
+		Members: *members,
+	}
+
+	log.Printf("processing...\n")
+	beg := time.Now()
+	sens, err := sa.Run()
+	if err != nil {
+		log.Fatalf("error running sensitivity analysis: %v\n", err)
+	}
+	log.Printf("processing... [done]: %v\n", time.Since(beg))
+
+	pop := sim.Population
+
+	o := io.Writer(os.Stdout)
+	if *oname != "" {
+		f, err := os.Create(*oname)
+		if err != nil {
+			log.Fatalf("error creating %s: %v\n", *oname, err)
+		}
+		defer f.Close()
+		o = f
+	}
+	err = writeTable(o, pop, sens)
+	if err != nil {
+		log.Fatalf("error writing sensitivity coefficients: %v\n", err)
+	}
+
+	if *pname != "" {
+		err = plotSensitivities(*pname, sa, pop, sens)
+		if err != nil {
+			log.Fatalf("error plotting sensitivity coefficients: %v\n", err)
+		}
+	}
+}
+
+// writeTable writes the sensitivity coefficients and their uncertainties
+// to w, one line per pair of nuclei and one column per nucleus.
+func writeTable(w io.Writer, pop []sim.Nucleus, sens []sim.Sensitivity) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%-12s", "# pair")
+	for _, n := range pop {
+		fmt.Fprintf(bw, " %16s", n.Name())
+	}
+	fmt.Fprintf(bw, "\n")
+	for _, s := range sens {
+		fmt.Fprintf(bw, "%-12s", s.Pair[0].Name()+"+"+s.Pair[1].Name())
+		for i := range pop {
+			fmt.Fprintf(bw, " %+7.3f ± %6.3f", s.Coeffs[i], s.Errs[i])
+		}
+		fmt.Fprintf(bw, "\n")
+	}
+	return bw.Flush()
+}
+
+// sensGrid is the matrix of sensitivity coefficients of the nuclei (columns)
+// to the pairs of nuclei (rows).
+type sensGrid struct {
+	sens []sim.Sensitivity
+	n    int
+}
+
+func (g sensGrid) Dims() (c, r int)   { return g.n, len(g.sens) }
+func (g sensGrid) Z(c, r int) float64 { return g.sens[r].Coeffs[c] }
+func (g sensGrid) X(c int) float64    { return float64(c) }
+func (g sensGrid) Y(r int) float64    { return float64(r) }
+
+// plotSensitivities saves a heatmap of the sensitivity coefficients into
+// the file named fname.
+func plotSensitivities(fname string, sa sim.SensitivityAnalysis, pop []sim.Nucleus, sens []sim.Sensitivity) error {
+	p, err := plot.New()
+	if err != nil {
+		return err
+	}
+
+	p.Title.Text = fmt.Sprintf(
+		"Sensitivity of yields to cross-sections C%v-O%v (N=%d, members=%d)",
+		sa.Engine.NumCarbons,
+		100-sa.Engine.NumCarbons,
+		sa.Engine.NumIters,
+		sa.Members,
+	)
+
+	xticks := make([]plot.Tick, len(pop))
+	for i, n := range pop {
+		xticks[i] = plot.Tick{Value: float64(i), Label: n.Name()}
+	}
+	yticks := make([]plot.Tick, len(sens))
+	for i, s := range sens {
+		yticks[i] = plot.Tick{Value: float64(i), Label: s.Pair[0].Name() + "+" + s.Pair[1].Name()}
+	}
+	p.X.Tick.Marker = plot.ConstantTicks(xticks)
+	p.Y.Tick.Marker = plot.ConstantTicks(yticks)
+
+	max := 1e-3
+	for _, s := range sens {
+		for _, v := range s.Coeffs {
+			max = math.Max(max, math.Abs(v))
+		}
+	}
+	cmap := moreland.SmoothBlueRed()
+	cmap.SetMin(-max)
+	cmap.SetMax(+max)
+
+	hm := plotter.NewHeatMap(sensGrid{sens, len(pop)}, cmap.Palette(64))
+	hm.Min = -max
+	hm.Max = +max
+	p.Add(hm)
+
+	figX := 25 * vg.Centimeter
+	return p.Save(figX, figX, fname)
+}
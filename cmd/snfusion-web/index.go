@@ -68,7 +68,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _indexHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xbc\x59\xff\x8e\x1b\xb7\x11\xfe\x7b\xf5\x14\x34\x83\x24\x12\xe4\x5d\xdd\x9d\x9d\xc0\xd0\xaf\xb4\x49\x9b\xd6\x6d\xea\x1a\x71\x8b\x02\xb5\x0f\x01\xb5\x4b\x49\xf4\x71\x97\x0b\x92\x2b\x9d\x6a\xdc\xbb\x77\x86\x5c\x4a\x5c\x9d\x74\xbe\x16\xc9\x19\x08\x6e\x49\x0e\xbf\x19\x0e\x67\xbe\x19\x2a\xd3\x67\x85\xca\xed\xae\xe6\x64\x6d\x4b\x39\xef\xf5\xa6\xed\xdf\x64\xba\xe6\xac\x98\xf7\x92\x64\x5a\x72\xcb\x48\xbe\x66\xda\x70\x3b\xa3\x8d\x5d\xa6\xaf\xe8\xc8\xad\x58\x61\x25\x9f\xbf\x6b\x6a\xae\xdf\xa8\x0d\xe3\xe4\xc7\xc6\x08\x55\x4d\x47\x7e\x61\xbf\xb9\x62\x25\x9f\xd1\x8d\xe0\xdb\x5a\x69\x4b\x49\xae\x2a\xcb\x2b\x00\xdb\x8a\xc2\xae\x67\x05\xdf\x88\x9c\xa7\x6e\xf0\x9c\x94\xa2\x12\x65\x53\xa6\x26\x67\x92\xcf\x2e\xb3\x8b\xe7\x04\x66\xac\x60\x32\x9e\x6a\x0c\xd7\x6e\xcc\x16\x30\xb5\xe3\x86\x12\x6f\x93\xc9\xb5\xa8\x2d\x31\x3a\x9f\xd1\xb5\xb5\xb5\x19\x8f\x46\x79\x51\x65\x9a\x6d\x57\xc2\x66\xb9\x2a\x47\x85\xda\x56\x52\xb1\x62\x54\x2b\xb9\x2b\x01\x07\xd6\x47\x97\xd9\x55\xf6\x22\xbb\x1a\x49\xb1\x18\x6d\xf9\x02\xe4\x6a\x55\x81\x91\xe6\xa3\xe9\x8e\xb3\x8f\x86\xce\xa7\x23\xaf\x07\x3c\x85\x4a\xa5\xa8\x6e\x88\xe6\x72\x46\x45\xe9\x8f\xb8\xd6\x7c\xf9\xff\x1b\xd0\xce\x87\xbf\x19\x5e\x4a\xeb\xf3\x5f\x5b\x95\xd0\xaa\x4a\x05\xdc\x88\x89\x3e\xbd\x42\xf2\x5b\x6a\xac\xea\xc6\x46\x9f\xbf\xa9\xc6\x9a\x41\x84\xa6\x8b\xc6\x5a\x55\x75\x06\x4f\xa0\xd5\x1f\x34\xfa\x7e\x02\x9d\xa6\x16\x55\x85\xe1\x13\x8f\x9e\x40\xaf\x55\xcc\xd8\xf8\xfb\x49\x74\x2a\xb9\x60\xba\x3b\x7a\x0a\x1f\xe7\x5a\x49\x99\x22\x4b\xc2\xa8\x66\x15\x97\xe7\x57\x9e\x22\xce\x20\x6f\xbb\x21\x1e\xcd\x3c\x85\x3f\xec\x4e\x72\x33\xca\x95\x54\x91\xfb\x1d\x23\xe3\x0a\x68\x3e\xeb\x1d\xf2\xa9\x47\xdc\xbf\x5a\x19\x20\x7a\x55\x8d\x09\x5b\x18\x25\x1b\xcb\x27\xed\x8a\x55\xf5\x98\x5c\x84\x91\x16\xab\xb5\x8d\xc6\x0b\x05\x87\x2c\xa3\x09\xc9\x97\x9d\x75\x96\xdf\xac\xb4\x6a\xaa\x22\x75\xf6\x8d\xc9\x86\xe9\x7e\x9a\x7a\x8b\x56\x9a\xef\xd2\xab\x0b\x28\x2a\x5f\x70\xce\x07\x7e\xd3\x5d\xcf\xfd\xe9\x44\xd5\xde\xce\x73\x78\x2b\xa5\x56\x92\xa7\x0b\xd9\xf0\xf4\x1b\x07\xf8\xf2\xea\xd5\x37\xcb\x97\x0f\x61\x66\xae\x56\xee\xa1\x4b\xa6\x57\x02\x1c\x70\x41\x5e\xd5\xb7\x27\xb6\x9d\x72\x5f\xd6\xd6\xd3\x83\x1f\x59\x51\x88\x6a\x35\x3e\x83\x11\x05\xc6\x7e\x4b\x70\x46\xb4\x06\x74\x75\x13\xce\xb7\x5d\x8b\x70\x19\x2d\x54\x66\x6a\x96\xf3\x83\x4f\x7e\xc7\xea\x5a\xee\xc0\x09\x92\xed\x54\x63\xd3\xa5\xe4\xb7\x87\x73\x27\x11\x01\xc2\x96\x24\x29\x84\xa9\x41\x72\x4c\x16\x52\xe5\x37\x93\x5e\x82\x42\x0b\x55\xec\xdc\xea\xfe\x00\x2f\x2f\xf0\x04\x6e\xb1\x10\x9b\xe8\xa0\x49\xe2\xda\x85\x31\xf9\xf6\xe2\xcb\x56\x20\xcb\x61\xc9\x59\x94\x24\xc1\x8d\xac\xb1\x6a\x72\x2c\x0c\x7a\x34\x78\x6f\x4c\x2e\xeb\x5b\x02\x71\x26\x8a\x49\xac\xf3\xf2\xa0\x33\xae\x15\xef\x35\x13\x86\x17\xd7\x99\x73\xc9\xb2\x91\x4e\xd1\xfd\x50\x68\xef\x1c\x21\xc3\xcc\x72\xb9\xf4\x80\xd0\x56\x8d\xda\x74\xe8\x85\x4e\x05\xbb\xaf\x19\xb5\xfc\xd6\x8e\x3e\xb2\x0d\xf3\xb3\x14\x04\x20\xa6\xc0\xba\xfc\x86\xcc\x48\xd5\x48\x39\x71\x13\x5b\xd3\x68\x01\x33\x74\x8b\xa9\xfa\xe9\x53\xf6\xfb\xa2\xd0\x77\x77\xa3\x82\x59\x46\xbd\x88\xa9\x96\xae\x0d\xfb\x45\x14\x20\x08\x49\xd0\x5b\x36\x55\x8e\x79\x45\x4c\xb3\x28\x85\xfd\x51\xe9\xb2\x2f\x8a\x81\xbb\x3b\xe8\xff\x9a\x12\x1c\x97\xad\xb8\xfd\xa3\xe4\xf8\xf9\xfd\xee\x75\xd1\xff\x3a\xc0\xa4\x7e\x53\xba\x84\x5d\xe9\xd7\x43\xd8\x97\xf9\x99\x3e\x5c\x2f\x9c\x69\x2b\x2a\xa0\x88\x4c\x39\x92\x00\x85\x41\x59\x1f\xf1\x13\xec\x26\x94\xe4\x99\x54\xab\x3e\xf5\x32\x14\xf6\xf5\x92\xbd\x4d\x7f\xe5\xbb\xb7\x9a\x1b\xd3\xe7\x6e\x43\x82\x47\xe0\x1b\xab\x16\x1f\x01\xac\x05\xe7\x1b\x30\xeb\x3b\xe2\xfe\x90\x31\xe1\xe8\x5c\xb1\x24\x7d\x2f\x97\xdd\xf0\xdd\x0f\xaa\xe0\x64\x36\x23\x97\x2f\xc8\x57\x5f\xb5\xfb\xb3\xdc\x6a\x09\xf0\x1e\x37\x09\x07\xfa\x13\xaf\xd0\xf4\x24\xb9\x73\x97\x92\xec\x3d\xa0\x2a\x00\x42\xba\x03\xc5\xc1\x2a\x34\x35\x5c\x02\xdf\x92\x7f\xf1\xc5\x3b\x18\x71\xdb\x77\x17\x31\x08\xcb\xb0\x57\xd5\xbc\xba\x77\xfc\xee\xf9\x61\x50\xf1\xdc\xf2\x02\xf8\x8c\x50\x32\x24\x01\xc4\x05\x5b\x8b\x93\x4b\x65\x78\x0c\xc4\xcf\x23\xa1\xff\x9c\x7c\x41\xfa\x88\xc7\x21\x38\xc1\x0f\x43\x42\x07\xf4\x08\xb6\x84\xc3\xb0\xd5\x29\x60\x74\xb8\xf7\xf6\x5f\xde\xfd\xfd\x4d\x56\x63\xa3\xdf\xe7\x19\x46\x94\x73\x13\xae\xbb\x50\x42\x97\xfa\x5c\x41\xdf\xc3\xd4\xb3\x59\x1c\x6c\xad\x9b\xa1\x45\xd7\xb6\x4f\x45\xb5\x61\x90\x59\x04\x7a\x67\xe3\x3c\x46\x10\x70\x0c\x48\x33\x0a\x41\x34\xa4\xcf\xc9\x96\xe1\x1b\x60\x18\x41\x0c\xe9\x33\x67\x77\x92\x68\x6e\x1b\x5d\xb9\x5b\x82\xff\xcc\x56\xd8\x7c\x4d\xfa\x60\xc1\x7b\x6a\x2c\x9c\x83\x5e\xb7\xea\x72\x06\xde\xa2\x2b\x5e\xa5\x05\xf4\xe7\x74\x8c\x73\xc9\xb9\xa0\xa6\x46\x94\xa1\x1f\xa2\x83\x8c\x81\x1f\x36\xce\x25\x4c\x1a\x17\x54\xfe\x68\x4e\x0d\xd7\x9a\x5e\xe3\x11\x31\xf9\x5a\x65\x0f\x21\x87\x74\x41\x53\x3a\x87\x1a\x64\x4e\xdf\x9f\xff\xf1\xb7\x9f\x82\x8f\x8d\xd5\x40\x35\x62\xb9\x8b\x54\xf9\x73\xbb\xe3\x46\x56\x94\x66\x75\xc2\x0a\xbc\x12\x64\x0c\x24\x82\x69\xad\x39\x71\xa4\x32\xfb\xe0\x68\x24\x05\xbf\xaf\x80\xfb\xb0\x08\x7e\xa0\x73\x3a\x39\xec\x01\xb4\xf6\x1e\xe1\x0b\x18\x5c\x42\x16\xd3\x0f\x55\xeb\xf3\x24\x81\x1c\x87\x8b\x75\xac\x41\x04\x99\xa2\x7c\x26\x79\xb5\xb2\x6b\x18\x0f\x87\xa0\x9f\x78\xc1\xc4\x69\x1f\xce\x50\xe2\xbd\xb8\xc6\x88\x9b\x2e\xf4\x5e\x97\x3f\xc4\x5e\x88\x4e\x47\x60\xe4\x9c\xfe\x4a\x2e\x44\xd8\xd8\x59\x0b\xcd\xd9\xcd\xe4\x10\x0c\xb5\x54\xf6\x71\xd1\x10\x14\xba\x1d\x0f\x68\xf4\x71\xb7\x81\xab\x98\x9c\xd4\xf8\x1f\x51\xc7\x0a\xd1\xd5\x50\xae\x60\xe3\xe7\x55\x6b\x8e\x3d\xd8\x91\xf2\xc9\x01\x46\xc6\x28\x39\xe8\xb5\xbc\x05\xea\x53\xa4\xe4\x70\x77\x85\xcc\x5c\x92\xd2\x93\xbc\x8d\xe4\xe0\x53\xd7\x49\xc2\xab\x7c\xad\x20\x11\xa1\xe1\xb2\x74\x3f\xcb\x1c\x2d\xf8\x46\xb0\x5b\x5c\xda\x16\xf0\xbb\xa5\x80\x28\xa3\x43\x5e\x21\xcd\xfc\xf3\xe7\xd7\x18\xa3\x19\x76\x8f\x91\x11\x91\xdb\x20\x36\xa3\x02\x4a\x7c\x01\x25\x48\x71\x22\xbf\x81\x68\x8d\xca\x91\xe7\x84\xc1\x07\x0a\x7c\xc6\x8c\x81\xc5\x50\x66\x61\x0a\x38\xe3\xc3\xe1\x5c\xc1\x9a\xf4\x88\x3a\x20\xd6\xff\xd0\x2e\x91\x7f\xbf\x7e\x8b\xf1\xd8\x7f\x27\xca\x46\x32\xc7\x93\x29\x69\x75\x4c\x3b\x4f\xc0\x10\xb3\xd8\x5e\x40\x0b\xc3\xab\xe2\x87\xb5\x90\x45\xbf\x90\x83\x09\xe9\x5e\xf6\x1d\x12\xea\x5d\xa7\x9e\xc6\x25\x05\x93\x33\x36\x68\x38\xe9\xfd\xcf\x44\x64\x75\x83\x3c\xe4\x6e\x1e\xe8\x12\x66\x30\xe3\xa9\x28\xe8\x38\xa6\xda\xe7\x38\x59\x35\xe5\x2f\xd0\x95\x69\x03\x6b\x6f\x9a\x72\xc1\x75\xff\xac\x3a\x90\x4d\xbd\xec\x20\x03\x5e\x6e\xf8\x60\x0f\x91\x33\xbd\x80\xd2\xf2\x48\x90\x20\xdd\x81\x31\x9c\x17\x8f\xd8\xef\xc4\xc2\x46\x57\x7a\x3b\x35\xcd\xd7\x07\x3a\x3c\x62\x48\x57\x87\x06\xc1\x29\xdd\xac\x3a\xca\x07\x58\x75\xe9\x80\x77\x79\x94\x0c\x21\xcd\x20\x0f\x22\x3f\xb6\xa2\x9d\x90\x5d\xeb\xf9\x74\x7d\x75\x14\x73\xa7\xb2\xd4\x45\x5c\x37\xc0\xba\xe8\x8e\x15\x47\xeb\x2b\x0c\x31\x6f\xfe\xca\xb5\x08\xe7\xcc\xaf\x9d\xf1\x20\x73\x6c\xbc\xe3\xc3\x2e\x76\x40\x44\xe6\xfa\x2c\x24\x0a\x1d\x63\x7a\xca\x3b\x06\xbd\x97\x06\xa0\x7a\x70\x62\x1a\x77\x0f\x82\x0d\x10\xcb\xe6\x51\x5c\x07\x82\xce\x1e\xdc\x00\x5e\x37\xd0\x28\x7c\xcf\x81\x9f\x78\x1f\xf0\x9f\x3b\x9c\x2c\x47\xfc\x37\x40\x2f\xe6\xfd\xc5\xf5\xa1\xbf\x32\xa0\xba\x7f\x26\x32\xee\x7a\xd1\xcf\x71\xd0\x61\xfb\x5f\x2e\xe1\xcb\xbd\x27\x9a\x0a\xfa\x38\x25\x37\xbc\xf0\xcf\xd1\xf3\xef\xa8\xa5\xb8\x6d\x85\x82\x54\xfb\x48\x9b\x3b\x1e\x98\xde\x7f\x3d\xe1\xf7\x8c\x32\xad\xd5\x36\xc5\x97\x00\xfe\x30\x78\x4f\xaa\xdd\x8d\xa1\xeb\xb9\x8d\xb6\x4f\x27\xf7\xf2\xa3\x73\x53\xa5\xcb\xf6\x77\x53\x90\xf9\x8c\x2e\xc3\x99\xce\xd7\x0f\xe9\x39\xb7\xb3\x04\x3f\xa7\x1b\x70\xf9\xf9\xcd\xd3\xd1\xd1\xb1\xbd\x2f\x22\xcb\xc3\x03\xac\x13\x9a\xd0\x31\xb2\xd2\xd0\xfb\xe7\xf4\x0f\xb2\x76\x21\x79\x2b\x39\x16\x4b\x53\xf3\x1c\x6e\x8f\xd8\x35\xc7\x1b\x0f\xd9\xe3\x40\x38\x52\x54\x96\x65\x7e\x03\x32\x78\xfb\x75\x16\x74\x7f\x5e\xf7\xb2\xc4\xe6\xf2\x40\x75\x44\xb2\x05\xfe\xcc\xf1\x05\x69\xc7\x8e\x79\x66\xf4\xf2\x02\xfe\x45\x5e\xc0\xad\x0f\xc2\x05\xd2\x0b\x80\x5f\x12\x3f\x43\x98\x55\xe5\x01\xf7\xdb\x47\x83\x3a\x26\x0c\x68\x7e\x10\x6c\xbb\x7a\xf1\xf2\x34\x4a\x14\x1d\xb1\x67\xbc\x3b\x8e\x34\x75\x0b\xee\xfe\xee\x7c\x41\xa5\xfb\x0a\x4c\x3b\xf5\x8b\x7a\xd3\xc2\xcd\x4a\x06\x55\x0e\x22\xed\x27\xf7\x37\xba\xaa\xa3\xfa\x19\x34\x2f\x8e\x8d\x68\xcb\x1b\x61\x12\xba\xfc\x9f\x9b\xaa\x82\xb4\x8d\x60\xe0\x9a\x5b\x8d\x51\x29\xdc\x9f\xbc\x9d\xd8\x43\x62\x00\x74\xcc\x43\x2e\xd9\xaf\xc6\xae\x19\xc5\x1e\x39\xac\xb4\x5f\xbd\x43\x94\x9f\xa0\x80\xb9\x7f\xa2\x23\x6f\xe0\x0b\x7d\xe4\xff\x67\xc8\x7f\x03\x00\x00\xff\xff\xa8\x2a\x2a\x03\x25\x19\x00\x00")
+var _indexHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xbc\x59\x61\x6f\xe3\x36\xd2\xfe\x2c\xfd\x0a\x2e\x8b\xb6\x32\x1c\xc9\x4e\x76\x5b\x2c\x6c\xcb\x7d\xdf\xb6\xd7\xbb\xbd\xb6\x7b\x8b\xee\x1d\x0e\xb8\xcd\x62\x41\x89\x23\x99\x09\x45\x0a\x24\x65\xc7\x17\xf8\xbf\x1f\x48\x4a\xb6\xe4\xd8\xd9\xdc\xa1\x4d\xbe\x58\x22\x67\x9e\x19\x0e\x67\x9e\x21\x95\xc5\x0b\x2a\x73\xb3\xad\x01\xad\x4c\xc5\x97\x61\xb8\x68\x7f\x83\xc5\x0a\x08\x5d\x86\x41\xb0\xa8\xc0\x10\x94\xaf\x88\xd2\x60\x52\xdc\x98\x22\x7e\x8d\x27\x6e\xc6\x30\xc3\x61\xf9\xbe\xa9\x41\xbd\x95\x6b\x02\xe8\xa7\x46\x33\x29\x16\x13\x3f\xb1\x57\x16\xa4\x82\x14\xaf\x19\x6c\x6a\xa9\x0c\x46\xb9\x14\x06\x84\x49\xf1\x86\x51\xb3\x4a\x29\xac\x59\x0e\xb1\x7b\xb9\x40\x15\x13\xac\x6a\xaa\x58\xe7\x84\x43\x7a\x99\x4c\x2f\x10\x13\xcc\x30\xc2\xfb\x43\x8d\x06\xe5\xde\x49\xc6\x21\xdd\x82\xc6\xc8\xfb\xa4\x73\xc5\x6a\x83\xb4\xca\x53\xbc\x32\xa6\xd6\xb3\xc9\x24\xa7\x22\x51\x64\x53\x32\x93\xe4\xb2\x9a\x50\xb9\x11\x5c\x12\x3a\xa9\x25\xdf\x56\xa0\xe2\x9c\x8a\xc9\x65\x72\x95\xbc\x4c\xae\x26\x9c\x65\x93\x0d\x64\xb9\xac\x6a\x29\x40\x18\x7d\xa3\x87\xef\xc9\x8d\xc6\xcb\xc5\xc4\xdb\x59\x86\xa1\x35\xca\x99\xb8\x45\x0a\x78\x8a\x59\xe5\x97\xb8\x52\x50\xfc\xef\x0e\xb4\xe3\xdd\x6f\x62\x37\xa5\x8d\xf9\xef\x6d\x8a\x29\x29\x62\x96\x4b\xa1\x7b\x8f\xde\x20\xfa\x23\x2d\x8a\xba\x31\xbd\xc7\x3f\xd4\x62\x4d\x6a\x50\x71\xd6\x18\x23\xc5\xe0\xe5\x19\xac\xfa\x85\xf6\x9e\x9f\xc1\xa6\xae\x99\x10\xa0\x86\x6f\xcf\x60\xd7\x48\xa2\x4d\xff\xf9\x59\x6c\x4a\x9e\x11\x35\x7c\x7b\x8e\x18\xe7\x4a\x72\x1e\x5b\x96\x04\x15\xd7\x44\x00\x3f\x3f\xf3\x1c\x79\x96\x4b\x31\x4c\xf1\xde\xc8\x73\xc4\xc3\x6c\x39\xe8\x49\x2e\xb9\xec\x85\xdf\x31\xb2\x9d\x59\x86\xc1\xd9\xe8\xa0\xfb\x10\xb9\xbf\x5a\x6a\x66\x98\x14\x33\x44\x32\x2d\x79\x63\x60\xde\xce\x18\x59\xcf\xd0\xb4\x7b\x53\xac\x5c\x99\xde\x7b\x26\x8d\x91\x55\x6f\x80\x43\x31\x98\x27\xf9\x6d\xa9\x64\x23\x68\xec\xfc\x9b\xa1\x35\x51\x51\x1c\x7b\x8f\x4a\x05\xdb\xf8\x6a\x3a\xbd\x40\x5f\x00\xc0\xc8\x2b\xed\x42\xf7\x33\xc8\x2a\x74\xff\x19\xbc\x52\xca\x92\x43\x9c\xf1\x06\xe2\x6f\x1c\xe0\xab\xab\xd7\xdf\x14\xaf\x1e\xc3\x4c\x5c\xaf\xdc\x43\x57\x44\x95\x4c\xcc\xd0\x14\xbd\xae\xef\x4e\xa8\x9d\x0a\x5f\xd2\xf6\xd3\x43\x1c\x09\xa5\x4c\x94\xb3\x33\x18\xbd\xc4\xd8\xab\xc4\xf1\x83\xb9\x98\x89\xdb\x6e\x7d\x9b\x15\x33\x30\x80\x4a\x74\x4d\x72\x38\xc4\xe4\xff\x48\x5d\xf3\x6d\x14\xc7\x9c\x6c\x65\x63\xe2\x82\xc3\xdd\x61\xdd\x41\x8f\x00\xd1\x7d\x18\x04\x94\xe9\x9a\x93\xed\x0c\x65\x5c\xe6\xb7\xf3\x30\xb0\x42\x99\xa4\x5b\x37\xbb\x5f\xc0\xab\x69\x7d\xd7\x4e\x52\xb6\xee\x2d\x34\x08\xdc\x71\x61\x86\xbe\x9d\x7e\xd9\x0a\x24\x39\x08\xe3\x3c\x0a\x82\x2e\x8c\xa4\x31\x72\x7e\x2c\x1c\x64\x52\x51\x50\x33\x74\x59\xdf\x21\x2d\x39\xa3\xf3\xbe\xcd\xcb\x83\xcd\x7e\xaf\xf8\xa0\x08\xd3\x40\x3f\x26\x2e\x24\x45\xc3\x9d\xa1\x87\xa9\xd0\xee\xb9\x85\xec\x46\x8a\xa2\xf0\x80\x61\xb0\x98\xb4\xe5\x10\x76\x27\x15\x7b\xfa\x4a\xb1\x81\x3b\x33\xb9\x21\x6b\xe2\x47\xf1\x32\x0c\xd7\x44\x21\x2d\xf3\x5b\x94\x22\xd1\x70\x3e\x77\x03\x1b\xdd\x28\x86\x52\x84\x37\xb6\x54\xef\xef\x93\xff\xa7\x54\xed\x76\x13\x4a\x0c\xc1\x5e\x44\x8b\xc2\x1d\xc3\x3e\x31\x8a\x52\x5b\x04\x61\xd1\x88\xdc\xd6\x15\xd2\x4d\x56\x31\xf3\x93\x54\x55\xc4\xe8\xc8\xed\x1d\x95\x79\x53\x81\x30\x49\x09\xe6\x4f\x1c\xec\xe3\xf7\xdb\x37\x34\xfa\xba\x83\x89\xbd\x52\x5c\x48\x55\xc5\x5f\x8f\x19\x1d\x25\x7e\x24\x1a\xcd\xc3\x5d\x18\x6e\x98\xa0\x72\x93\x48\x47\x12\x28\x45\x9d\xb1\xc8\xe2\x07\xf6\x34\x21\x39\x24\x5c\x96\x11\xf6\x32\x78\x34\x0f\xc3\x60\xef\xd3\xcf\xb0\x7d\xa7\x40\xeb\x08\x9c\x42\x60\x97\x00\x6b\x23\xb3\x1b\x94\xa2\x16\x1c\xd6\x20\xcc\x77\xc8\xfd\xa0\x19\x02\x1b\x5c\x56\xa0\xc8\xcb\x25\xb7\xb0\xfd\x41\x52\x40\x69\x8a\x2e\x5f\xa2\xaf\xbe\x6a\xf5\x93\xdc\x28\xfe\x33\x6c\x3d\x6e\xd0\x2d\xe8\xcf\x20\xac\xeb\x41\xb0\x73\x9b\x12\xec\x23\x20\xc5\x2d\x6c\x2d\xdd\xa1\x74\xef\x95\x75\xb5\xdb\x04\xd8\xa0\x7f\x42\xf6\x5e\xe6\xb7\x60\x22\xb7\x11\xa3\x6e\x3a\x91\x42\xd6\x20\x1e\x2c\x7f\xb8\xfe\x5c\x0a\x01\xb9\x01\x8a\x8c\x44\x18\x8d\x51\x07\x12\xec\x0e\x38\x39\x97\x1a\xfa\x40\x70\x1e\xc9\xc6\xcf\xc9\x53\x14\x59\x3c\x48\x72\x1b\x87\x31\xc2\x23\x7c\x04\x5b\x81\xd6\xa4\x3c\x05\x6c\x03\xee\xa3\xfd\xd7\xf7\x7f\x7b\x9b\xd4\xf6\xa0\x1f\x41\x62\x33\x6a\x34\x6f\xe7\x5d\x2a\xd9\x90\x32\xda\xc5\x9e\x51\xf4\x22\xed\x27\x5b\x1b\x66\xc2\x41\x99\x08\x33\xb1\x26\x9c\x51\xb4\x81\x4c\xbb\x88\x21\x0b\x38\x43\x8c\xa6\x78\xcc\xe8\x18\x5f\xa0\x0d\xb1\x77\x80\x71\x0f\x62\x8c\x5f\x38\xbf\x83\x40\x81\x69\x94\x70\xbb\x14\x06\x81\xde\x30\x93\xaf\x50\x24\xb3\x9b\x0f\x58\x1b\x52\x02\xfe\xd8\x9a\xcb\x89\x06\x84\x4b\x10\x31\x95\x02\xf0\xcc\x8e\x05\xe7\x92\x1a\x6b\x56\x75\xe7\x21\x3c\x4a\x48\x6e\xd8\xda\x85\x84\x70\xed\x92\xca\x2f\xcd\x99\x01\xa5\xf0\x47\xf4\xc2\x17\x5f\x6b\xec\x31\xe4\xae\x5c\xac\x2b\x83\x45\x8d\x12\x67\xef\x2f\x7f\xff\xf5\x97\x2e\xc6\xda\x28\x26\x4a\x56\x6c\x7b\xa6\xfc\xba\x83\xdd\xd0\x8b\x4a\x97\x27\xbc\xb0\x5b\x62\x19\xc3\x12\xc1\xa2\x56\x80\x1c\xa9\xa4\xd7\x8e\x46\x62\xc2\x59\x29\x66\xae\x09\x5e\xe3\x25\x9e\x1f\x74\x2a\x5d\xb6\xfb\x58\xe9\x32\xd1\x35\x67\x26\xc2\xd7\xa2\x8d\x79\x10\x14\x52\xa1\x88\x39\xd6\x40\x0c\x2d\xac\x7c\xc2\x41\x94\x66\x35\x47\x6c\x3c\x1e\xa1\x7b\xe4\x05\x03\x67\x7d\x9c\x5a\x89\x0f\xec\xa3\xcd\xb8\x45\xa6\xf6\xb6\x76\xe1\x40\x08\x2f\x26\xb5\x82\x25\xfe\x9d\x42\x68\x61\xfb\xc1\xca\x14\x90\xdb\xf9\x21\x19\x6a\x2e\xcd\xd3\xb2\xa1\x33\xe8\x34\x1e\xb1\xe8\xf3\x6e\x6d\xb7\x62\xec\x5f\x48\x9e\x7f\x72\x03\xf3\x93\x2e\xfc\x9b\xd5\x7d\x0f\x6c\xec\x29\x5b\xa3\x14\x7d\xde\x17\x05\xf6\x50\x76\xe4\xcd\xfc\x00\xc3\xfb\x28\xb9\x02\x62\xa0\x05\x8a\xb0\xe5\xe8\x6e\x33\x29\x4f\x5c\xd5\xe2\x93\x44\x6e\xd9\x82\xd1\xbd\x64\x05\x66\x25\x69\x8a\x6b\xa9\x0d\xde\x8f\x12\xc7\x13\xfe\x64\x38\xec\x36\xed\x99\xf0\xbb\x82\x71\x48\xf1\x18\x84\xe5\x9d\x7f\xfc\xf6\xc6\x26\x6d\x62\x8f\x93\x3d\x27\x7a\x71\xc4\x8b\x7e\x47\x45\xbe\xa3\x22\xcb\x79\x2c\xbf\x4d\xaf\x71\xaf\x3f\x79\x92\x18\x5d\x63\x94\x73\xa2\x75\x7a\x8d\xbb\xbe\x7b\x8d\x2d\x89\x5c\x1f\xd6\xd5\x79\x13\x1f\x71\xc9\x35\x5e\xfe\xd8\x4e\xa1\x7f\xbd\x79\x67\x13\x34\x7a\xcf\xaa\x86\x13\x47\x9c\x31\x6a\x6d\x2c\x06\x77\xc2\x2e\x89\xed\x79\x83\xd4\x35\x08\xfa\xc3\x8a\x71\x1a\x51\x3e\x9a\xa3\xe1\x66\xef\x2c\xc3\xee\x06\x0d\xb6\xdf\x63\x6c\xb5\xf6\x1d\x1a\xcf\xc3\xff\x9a\x99\x8c\x6a\x2c\x31\xb9\x9d\x27\x86\xa0\xd4\x82\x06\x98\x51\x3c\xeb\x73\xef\x85\x1d\x14\x4d\xf5\x89\x19\x50\x1a\xcf\xd0\xdb\xa6\xca\x40\x45\x67\xcd\x89\xa6\x8a\xbd\xec\x28\x59\x13\xde\xc0\x68\x0f\x91\x13\x95\x49\xf1\x54\x90\x4e\x7a\x00\xa3\x01\xe8\x13\xf4\x9d\xd8\x40\xb1\x92\x14\x38\x9e\x9d\x2f\x13\x2f\xd0\xea\xb8\xf6\x3d\xe8\x8b\xbe\xc7\xe0\xf1\x11\xcb\xba\x5e\x36\x9a\x87\xa7\x0a\xf1\xa8\x84\x28\x5b\xbb\x0a\xb2\xdb\x7f\x54\x3f\x5d\x65\xa2\x71\x3f\xf4\xad\xe8\x20\xcb\x57\x6a\xb9\x58\x5d\x1d\xa5\xe9\xa9\xc2\x76\x49\x3a\xcc\xc9\x21\xba\x63\xd6\xc9\xea\xca\x66\xa5\x77\xbf\x04\xf1\x88\xfb\xb5\x73\xbe\x04\x71\xec\xbc\xe3\xd4\x21\x76\x87\x68\xd9\xef\xb3\x90\x56\xe8\x18\xd3\xd3\xe6\x31\xe8\x83\xca\x29\x41\x8c\x4e\x0c\x5b\xed\x51\xe7\x83\x66\x95\x7e\x12\x3d\x6a\xe6\x19\xce\x2a\x24\x4c\x68\x50\xe6\x7b\x28\xa4\x82\x88\xb2\xf5\x85\xc3\x49\x72\x8b\xff\x56\x52\xd0\x1f\xa6\x1f\x0f\x67\x34\x0d\x82\x46\x67\x32\x63\x17\xf6\x3e\xe9\x05\x8b\x89\xff\xfa\x19\x06\x0b\x77\x27\x69\x84\x02\x2d\xf9\x1a\xa8\xbf\xd2\x9e\xbf\x8b\x15\xec\xae\x15\x0a\x16\x83\x8b\x9e\xbd\x7a\xef\xc7\xfa\x37\x30\xfb\x9c\x62\xa2\x94\xdc\xc4\xf6\x36\x61\x3f\x2e\x3e\x90\x6a\xb5\x6d\xea\x7a\x3a\xc4\xed\xf5\xcb\xdd\x1e\xf1\x52\x8b\xb8\x68\xbf\xbd\x52\xb6\xfe\x8c\x2d\x0d\x44\xe5\xab\xc7\xec\x9c\xd3\xac\xa4\x82\x78\x0d\xca\x9c\x57\x5e\x4c\x8e\x96\x1d\x86\x47\x9e\x77\x97\xb8\x41\x6a\xd6\x44\x91\x4a\xe3\x87\xeb\xf4\x97\xba\x76\x22\x78\xc7\xc1\xf6\x57\x5d\x43\xce\x8a\x2d\x32\x2b\x40\xfa\x50\x3d\x0e\x04\x2c\xab\x25\x49\xe2\x15\x2c\xe9\x87\xc1\xe3\xa0\xc1\xa2\x7f\x3b\xb5\x07\xd4\x03\x3b\x22\x4e\x32\xfb\xa9\xe4\x0b\xd4\xbe\x3b\xe2\x49\xf1\xe5\x74\x3a\x9d\xf6\xa2\x60\x55\x1f\x85\xeb\x78\xb2\x03\xfc\x12\xf9\x11\x44\x8c\xac\x0e\xb8\xdf\x3e\x19\xd4\x91\x67\x87\xe6\x5f\x3a\xdf\xae\x5e\xbe\x7a\x2a\x8a\xe7\xd3\x0e\xc6\x6f\x08\x72\x83\x28\xba\xbf\x4f\x7e\xb5\x4f\x7a\xb7\x1b\xed\xc1\xef\xef\x93\x1f\xa1\x20\x0d\x37\x6e\x6e\xb7\x3b\x6d\xa9\x97\x87\xfd\x3d\xf0\x81\x3f\xf2\x66\x78\x1a\xd8\x67\x89\xef\xf6\x78\x7f\x3c\xc0\x83\xe6\x8a\x7d\x10\xda\xa1\x98\x93\x46\xd8\x9c\xfe\xc5\xfd\xf6\x92\xe2\xa8\xb9\x87\xc1\x91\x4b\xfb\x62\xf6\xbd\x17\x11\x6e\x52\xfc\x5b\x23\x04\x13\x65\x0f\x26\x49\x92\xd6\x62\xaf\x4f\xef\x57\xde\x0e\xec\x21\x6d\xaa\x0d\xdc\xb3\xac\xb5\x9f\xed\x87\x66\xd2\x8f\xc8\x61\xa6\x7d\x0a\x0f\xf5\x74\x82\x6c\x96\xfe\x83\x82\x65\x28\xfb\x3d\x61\xe2\xff\x75\xf3\x9f\x01\x00\x4a\xa7\x84\xb1\xd3\x19\x00\x00")
 
 func indexHtmlBytes() ([]byte, error) {
 	return bindataRead(
@@ -83,7 +83,7 @@ func indexHtml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "index.html", size: 6437, mode: os.FileMode(420), modTime: time.Unix(1461331115, 0)}
+	info := bindataFileInfo{name: "index.html", size: 6611, mode: os.FileMode(420), modTime: time.Unix(1792384299, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
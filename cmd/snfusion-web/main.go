@@ -5,7 +5,6 @@ import (
 	"archive/zip"
 	"bytes"
 	"crypto/md5"
-	"encoding/csv"
 	"fmt"
 	"image/color"
 	"io"
@@ -17,10 +16,13 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"text/template"
 	"time"
 
+	"github.com/astrogo/snfusion/internal/snplot"
 	"github.com/astrogo/snfusion/sim"
+	"github.com/astrogo/snfusion/sim/snio"
 
 	"golang.org/x/net/websocket"
 
@@ -98,6 +100,16 @@ func (srv *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// srv.rootfs.ServeHTTP(w, r)
 }
 
+// Models returns the list of fusion models users may select.
+func (srv *server) Models() string {
+	return strings.Join(sim.Models(), ", ")
+}
+
+// DefaultModel returns the name of the fusion model selected by default.
+func (srv *server) DefaultModel() string {
+	return sim.DefaultModel
+}
+
 func (srv *server) dataHandler(ws *websocket.Conn) {
 	c := &client{
 		srv:   srv,
@@ -134,6 +146,7 @@ func (c *client) run() {
 		NumIters   int     `json:"num_iters"`
 		NumCarbons float64 `json:"num_carbons"`
 		Seed       int64   `json:"seed"`
+		Model      string  `json:"model"`
 	}
 
 	type genReply struct {
@@ -145,10 +158,11 @@ func (c *client) run() {
 	}
 
 	type plotReply struct {
-		ID    int    `json:"id"`
-		Stage string `json:"stage"`
-		Err   error  `json:"err"`
-		SVG   string `json:"svg"`
+		ID     int    `json:"id"`
+		Stage  string `json:"stage"`
+		Err    error  `json:"err"`
+		SVG    string `json:"svg"`
+		AccSVG string `json:"acc_svg"`
 	}
 
 	type zipReply struct {
@@ -163,6 +177,7 @@ func (c *client) run() {
 			NumIters:   100000,
 			NumCarbons: 60,
 			Seed:       1234,
+			Model:      sim.DefaultModel,
 		}
 
 		log.Printf("waiting for simulation parameters...\n")
@@ -179,6 +194,8 @@ func (c *client) run() {
 			NumIters:   param.NumIters,
 			NumCarbons: param.NumCarbons,
 			Seed:       param.Seed,
+			Model:      param.Model,
+			Acceptance: true,
 		}
 		engine.SetLogger(msg)
 
@@ -212,33 +229,36 @@ func (c *client) run() {
 		copy(csvdata, csvbuf.Bytes())
 
 		log.Printf("running post-processing...\n")
-		r := csv.NewReader(csvbuf)
-		r.Comma = ';'
-		r.Comment = '#'
+		r, err := snio.NewReader(csvbuf)
+		if err != nil {
+			log.Printf("error reading metadata: %v\n", err)
+			return
+		}
 
 		table := make([]plotter.XYs, len(engine.Population))
 		for i := range table {
-			table[i] = make(plotter.XYs, engine.NumIters+1)
+			table[i] = make(plotter.XYs, 0, engine.NumIters+1)
 		}
 
-		for ix := 0; ix < engine.NumIters+1; ix++ {
-			var text []string
-			text, err = r.Read()
-			if err != nil {
-				break
-			}
-			for i := range engine.Population {
-				table[i][ix].X = float64(ix)
-				table[i][ix].Y = float64(atoi(text[i]))
-			}
-		}
-		if err == io.EOF {
-			err = nil
+		acc := make([]plotter.XYs, len(sim.AcceptanceColumns))
+		for i := range acc {
+			acc[i] = make(plotter.XYs, 0, engine.NumIters+1)
 		}
+
+		recs, err := r.ReadAll()
 		if err != nil {
 			log.Printf("error reading data: %v\n", err)
 			return
 		}
+		for _, rec := range recs {
+			x := float64(rec.Iter)
+			for i, m := range rec.Masses {
+				table[i] = append(table[i], plotter.XY{X: x, Y: float64(m)})
+			}
+			for i, v := range rec.Acceptance {
+				acc[i] = append(acc[i], plotter.XY{X: x, Y: float64(v)})
+			}
+		}
 
 		p, err := plot.New()
 		if err != nil {
@@ -289,8 +309,22 @@ func (c *client) run() {
 			return
 		}
 
+		accp, err := snplot.Acceptance(engine, acc)
+		if err != nil {
+			log.Printf("error plotting acceptance statistics: %v\n", err)
+			return
+		}
+		acccanvas := vgsvg.New(figX, figY)
+		accp.Draw(draw.New(acccanvas))
+		accsvg := new(bytes.Buffer)
+		_, err = acccanvas.WriteTo(accsvg)
+		if err != nil {
+			log.Printf("error svg: %v\n", err)
+			return
+		}
+
 		err = websocket.JSON.Send(c.ws, plotReply{
-			ID: id, Err: err, SVG: outsvg.String(), Stage: "plot-done",
+			ID: id, Err: err, SVG: outsvg.String(), AccSVG: accsvg.String(), Stage: "plot-done",
 		})
 		if err != nil {
 			log.Printf("error sending data: %v\n", err)
@@ -392,14 +426,6 @@ func init() {
 	rootfs = filepath.Join(gopath, "src/github.com/astrogo/snfusion/cmd/snfusion-web/rootfs")
 }
 
-func atoi(s string) int {
-	v, err := strconv.Atoi(s)
-	if err != nil {
-		panic(err)
-	}
-	return v
-}
-
 func label(n sim.Nucleus) string {
 	switch n {
 	case sim.Nucleus{A: 12, Z: 6}:
@@ -425,7 +451,7 @@ func label(n sim.Nucleus) string {
 	case sim.Nucleus{A: 56, Z: 28}:
 		return "56-Ni"
 	}
-	return n.String()
+	return fmt.Sprintf("%d-%s", n.A, n.Symbol())
 }
 
 func rgb(r, g, b uint8) color.RGBA {
@@ -0,0 +1,110 @@
+// Package snplot provides the plots shared by the snfusion commands.
+package snplot
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/astrogo/snfusion/sim"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+)
+
+// Acceptance creates a plot of the burning efficiency of a run out of
+// its acceptance statistics columns (see sim.AcceptanceColumns.)
+// The fractions of fusions and of rejections by cause are cumulative.
+// The population size is drawn relative to its initial value, and the
+// Y axis is extended beyond 1 when the population grows (e.g. with accretion.)
+func Acceptance(engine sim.Engine, acc []plotter.XYs) (*plot.Plot, error) {
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+
+	p.Title.Text = fmt.Sprintf(
+		"Burning efficiency C%v-O%v (seed=%d)",
+		engine.NumCarbons,
+		100-engine.NumCarbons,
+		engine.Seed,
+	)
+	p.X.Label.Text = "Iteration number"
+	p.Y.Label.Text = "Fraction"
+	p.Y.Min = 0
+	p.Y.Max = 1
+
+	const (
+		attempts = iota
+		self
+		nofusion
+		unknown
+		rejected
+		size
+	)
+
+	n := len(acc[attempts])
+	fracs := []struct {
+		name string
+		data plotter.XYs
+	}{
+		{"fusions", make(plotter.XYs, n)},
+		{"self-pairs", make(plotter.XYs, n)},
+		{"no fusion product", make(plotter.XYs, n)},
+		{"unknown pairs", make(plotter.XYs, n)},
+		{"probability test", make(plotter.XYs, n)},
+		{"population size (relative)", make(plotter.XYs, n)},
+	}
+
+	size0 := 0.0
+	if n > 0 {
+		size0 = acc[size][0].Y
+	}
+	if size0 <= 0 {
+		// empty initial population: draw the size relative to its maximum.
+		for _, v := range acc[size] {
+			size0 = math.Max(size0, v.Y)
+		}
+	}
+	if size0 <= 0 {
+		size0 = 1
+	}
+
+	for i := 0; i < n; i++ {
+		x := acc[attempts][i].X
+		tot := acc[attempts][i].Y
+		if tot <= 0 {
+			// no attempt yet: all the fractions are zero.
+			tot = 1
+		}
+		fusions := acc[attempts][i].Y
+		for _, col := range []int{self, nofusion, unknown, rejected} {
+			fusions -= acc[col][i].Y
+			fracs[col].data[i].X = x
+			fracs[col].data[i].Y = acc[col][i].Y / tot
+		}
+		fracs[0].data[i].X = x
+		fracs[0].data[i].Y = fusions / tot
+		fracs[size].data[i].X = x
+		fracs[size].data[i].Y = acc[size][i].Y / size0
+		p.Y.Max = math.Max(p.Y.Max, fracs[size].data[i].Y)
+	}
+
+	for i, frac := range fracs {
+		line, err := plotter.NewLine(frac.data)
+		if err != nil {
+			return nil, err
+		}
+		line.LineStyle.Color = plotutil.Color(i)
+		line.LineStyle.Width = vg.Points(1)
+		p.Add(line)
+		p.Legend.Add(frac.name, line)
+	}
+
+	p.Add(plotter.NewGrid())
+	p.Legend.Top = true
+	p.Legend.XOffs = -1 * vg.Centimeter
+
+	return p, nil
+}
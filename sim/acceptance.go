@@ -0,0 +1,25 @@
+package sim
+
+// AcceptanceColumns are the names of the acceptance statistics columns
+// appended to the output records of an Engine when Acceptance is set.
+var AcceptanceColumns = []string{"attempts", "self", "no-fusion", "unknown", "rejected", "size"}
+
+// AcceptanceStats holds the cumulative number of fusion attempts of a run
+// and the number of rejected attempts, by cause.
+type AcceptanceStats struct {
+	Attempts int // number of fusion attempts
+	Self     int // attempts drawing the same nucleus twice
+	NoFusion int // attempts on pairs without fusion product
+	Unknown  int // rejected attempts on pairs without tabulated cross-section
+	Rejected int // rejected attempts on pairs with tabulated cross-section
+}
+
+// Fusions returns the number of successful fusion attempts.
+func (acc AcceptanceStats) Fusions() int {
+	return acc.Attempts - acc.Self - acc.NoFusion - acc.Unknown - acc.Rejected
+}
+
+// AcceptanceStats returns the acceptance statistics of the last run.
+func (e *Engine) AcceptanceStats() AcceptanceStats {
+	return e.acc
+}
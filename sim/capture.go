@@ -0,0 +1,86 @@
+package sim
+
+import "math"
+
+const (
+	massE  = 0.51099895 // electron mass, in MeV
+	deltaH = 0.78234707 // neutron-hydrogen mass difference, in MeV
+)
+
+// Capture describes electron captures, lowering the atomic number of
+// nuclei at fixed mass number.
+//
+// At each iteration, a nucleus drawn at random captures an electron
+// with the probability
+//
+//	P = Rate * ((Ef - Qec) / me)^2
+//
+// when the Fermi energy Ef of the degenerate electrons, given by the density
+// Conditions.Rho and the electron fraction Ye of the population, exceeds the
+// capture threshold Qec of the nucleus.
+// Products of captures must lie within the Chart of the simulation.
+type Capture struct {
+	Rate float64 // capture rate normalization (zero: no electron capture)
+}
+
+// Enabled returns whether electron captures are enabled.
+func (c Capture) Enabled() bool {
+	return c.Rate > 0
+}
+
+// FermiEnergy returns the kinetic Fermi energy, in MeV, of degenerate
+// electrons at the density rho, in g/cm^3, and electron fraction ye.
+func FermiEnergy(rho, ye float64) float64 {
+	x := 1.0088e-2 * math.Cbrt(rho*ye)
+	return massE * (math.Sqrt(1+x*x) - 1)
+}
+
+// CaptureThreshold returns the minimal electron energy, in MeV, for
+// the nucleus n to capture an electron.
+// Negative thresholds denote nuclei unstable against electron capture.
+func CaptureThreshold(n Nucleus) float64 {
+	d := Nucleus{A: n.A, Z: n.Z - 1}
+	return deltaH + BindingEnergy(n) - BindingEnergy(d)
+}
+
+// Ye returns the electron fraction of the population.
+func (e *Engine) Ye() float64 {
+	if e.totA == 0 {
+		return 0
+	}
+	return float64(e.totZ) / float64(e.totA)
+}
+
+// capture performs an electron capture attempt on a nucleus drawn at random.
+func (e *Engine) capture() {
+	if !e.Capture.Enabled() || len(e.nuclei) == 0 {
+		return
+	}
+
+	i := e.rng.Intn(len(e.nuclei))
+	n := e.nuclei[i]
+	if n.Z <= 0 {
+		return
+	}
+	o := Nucleus{A: n.A, Z: n.Z - 1}
+	if !e.Chart.Allows(o) {
+		return
+	}
+	ef := FermiEnergy(e.cond.Rho, e.Ye())
+	dq := ef - CaptureThreshold(n)
+	if dq <= 0 {
+		return
+	}
+	p := e.Capture.Rate * (dq / massE) * (dq / massE)
+	if e.rng.Float64() >= p {
+		return
+	}
+
+	e.decr(n)
+	e.counts[o]++
+	e.totZ--
+	e.addCaptured(i, o)
+	e.nuclei[i] = o
+	e.touch(n)
+	e.touch(o)
+}
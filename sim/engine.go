@@ -10,15 +10,13 @@
 //		Seed:       *seed,
 //	}
 //	err = engine.Run(w)
-//
 package sim
 
 import (
-	"encoding/csv"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"math/rand"
 	"os"
 	"sort"
@@ -42,7 +40,7 @@ var (
 		{A: 56, Z: 28}, // 56-Ni
 	}
 
-	// HeaderCSV identifies the start of meta-data
+	// HeaderCSV identifies the start of meta-data (see Metadata)
 	HeaderCSV = []byte("# snfusion-gen=")
 )
 
@@ -52,15 +50,53 @@ func itoa(i int) string {
 
 // Engine controls the time evolution of an SN-Fusion simulation.
 type Engine struct {
-	NumIters   int
-	NumCarbons float64
-	nuclei     []Nucleus
-	Seed       int64
-	Population []Nucleus
-	rng        *rand.Rand
-	w          io.Writer
-	wcsv       *csv.Writer
-	msg        *log.Logger
+	NumIters      int
+	NumCarbons    float64
+	nuclei        []Nucleus
+	Seed          int64
+	Population    []Nucleus
+	Model         string     // name of the registered fusion model (zero: DefaultModel)
+	ModelFile     string     // data file of the fusion model, if any (e.g. the REACLIB library of the "reaclib" model)
+	Chart         *Chart     // allowed fusion products (nil: DefaultChart)
+	Fallback      Fallback   // fusion probability of pairs without cross-section
+	Conditions    Conditions // thermodynamic conditions of the simulation
+	CheckEvery    int        // check invariants every CheckEvery iterations (zero: never)
+	RejectionFree bool       // use rejection-free kinetic Monte Carlo pair selection
+	Acceptance    bool       // append acceptance statistics columns to the records
+	TrackLineage  bool       // record the fusion history of all nuclei
+	Sources       []Source   // scheduled injections of nuclei
+	Sinks         []Sink     // scheduled removals of nuclei
+	Heating       Heating    // thermal evolution of the simulation
+	Capture       Capture    // electron captures
+	model         FusionModel
+	totA          int // total mass number of the population
+	totZ          int // total atomic number of the population
+	fallbacks     map[Pair]int
+	flux          map[Pair]*Flux
+	iter          int // current iteration
+	counts        map[Nucleus]int
+	initial       []Nucleus // species of the initial population
+	acc           AcceptanceStats
+	ids           []int // lineage IDs of the nuclei
+	lineage       []LineageNode
+	cond          Conditions // current thermodynamic conditions
+	energy        float64    // energy released by fusions, in MeV
+	arng          *rand.Rand // attribution of skipped attempts to pairs and rejection causes
+	rf            *rfState   // pending rejection-free step
+	err           error      // error of the step by step simulation
+	events        *EventWriter
+	rng           *rand.Rand
+	out           Output
+	msg           *log.Logger // logger set with SetLogger
+	logger        *log.Logger // logger of the current run
+}
+
+// chart returns the chart of nuclides of the simulation.
+func (e *Engine) chart() Chart {
+	if e.Chart == nil {
+		return DefaultChart
+	}
+	return *e.Chart
 }
 
 // SetLogger setups the logging output of the simulation engine.
@@ -68,46 +104,107 @@ func (e *Engine) SetLogger(msg *log.Logger) {
 	e.msg = msg
 }
 
+// SetEventLog enables the logging of all the fusion attempts of the
+// simulation into w.
+// See EventWriter for a description of the format.
+func (e *Engine) SetEventLog(w io.Writer) {
+	e.events = NewEventWriter(w)
+}
+
+// SetModel sets the fusion model driving the simulation engine,
+// overriding the one registered under the Model name.
+func (e *Engine) SetModel(m FusionModel) {
+	e.model = m
+}
+
 // Run runs the whole simulation and writes data (as well as
 // metadata) into w.
-// The data is written as a CSV file with '#' comments and ';' separators.
+// The data is written as a CSV file with '#' comments and ';' separators
+// (see CSVWriter).
+// The integrated reaction flux of the run is written as a trailer.
+//
+// When RejectionFree is set, Run uses a rejection-free (BKL) kinetic Monte Carlo
+// selection of reacting pairs: only reactions that happen are drawn,
+// weighted by their probability, and the iteration counter is advanced by
+// the number of attempts a rejection sampler would have needed.
+// Each skipped attempt is attributed to a pair of nuclei, or to the draw of
+// the same nucleus twice, according to the weights of the rejections, so
+// that the reaction flux, the event log and the acceptance statistics
+// follow the same distributions as with the standard sampling.
 func (e *Engine) Run(w io.Writer) error {
-	err := e.init(w)
+	return e.RunOutput(NewCSVWriter(w))
+}
+
+// RunOutput runs the whole simulation and writes data (as well as
+// metadata) into out.
+func (e *Engine) RunOutput(out Output) error {
+	err := e.init(out)
 	if err != nil {
 		return err
 	}
 
-	defer e.wcsv.Flush()
+	defer e.out.Flush()
+	if e.events != nil {
+		defer e.events.Flush()
+	}
 
-	e.msg.Printf("%v\n", e.stats())
+	e.logger.Printf("%v\n", e.stats())
 
-	for i := 0; i < e.NumIters; i++ {
-		err = e.process()
+	for e.iter < e.NumIters {
+		err = e.step(e.NumIters - e.iter)
 		if err != nil {
 			return err
 		}
-		if (i+1)%int(float64(e.NumIters)*0.1) == 0 {
-			e.msg.Printf("iter #%d/%d...\n", i+1, e.NumIters)
-		}
 	}
 
-	e.msg.Printf("%v\n", e.stats())
+	e.logger.Printf("%v\n", e.stats())
+	if e.Heating.Enabled() {
+		e.logger.Printf("released energy: %g MeV\n", e.energy)
+		e.logger.Printf("final temperature: T9=%g\n", e.cond.T9)
+	}
+	if e.Capture.Enabled() {
+		e.logger.Printf("final electron fraction: Ye=%g\n", e.Ye())
+	}
+	if len(e.fallbacks) > 0 {
+		e.logger.Printf("%v\n", e.fallbackReport())
+	}
+
+	err = e.out.WriteFlux(e.Flux())
+	if err != nil {
+		return err
+	}
 
-	e.wcsv.Flush()
-	err = e.wcsv.Error()
+	err = e.out.Flush()
 	if err != nil {
 		return err
 	}
 
+	if e.events != nil {
+		err = e.events.Flush()
+		if err != nil {
+			return err
+		}
+	}
+
 	return err
 }
 
-func (e *Engine) init(w io.Writer) error {
+func (e *Engine) init(out Output) error {
 	e.rng = rand.New(rand.NewSource(e.Seed))
-	e.w = w
-
-	if e.msg == nil {
-		e.msg = log.New(os.Stdout, "snfusion-sim: ", 0)
+	e.out = out
+	e.iter = 0
+	e.rf = nil
+	e.err = nil
+	e.cond = e.Conditions
+	e.energy = 0
+	e.acc = AcceptanceStats{}
+	e.arng = rand.New(rand.NewSource(e.Seed + 1))
+	e.fallbacks = make(map[Pair]int)
+	e.flux = make(map[Pair]*Flux)
+
+	e.logger = e.msg
+	if e.logger == nil {
+		e.logger = log.New(os.Stdout, "snfusion-sim: ", 0)
 	}
 
 	var err error
@@ -127,23 +224,67 @@ func (e *Engine) init(w io.Writer) error {
 		e.nuclei = append(e.nuclei, n)
 	}
 
-	if e.Population == nil {
-		e.Population = make([]Nucleus, len(Population))
-		copy(e.Population, Population)
+	e.counts = make(map[Nucleus]int)
+	for _, n := range e.nuclei {
+		e.counts[n]++
 	}
+	e.initial = e.species()
+	e.initLineage()
 
-	hdr, err := json.Marshal(e)
+	if e.Chart == nil {
+		chart := DefaultChart
+		e.Chart = &chart
+	}
+
+	err = e.Heating.validate(e.Conditions)
 	if err != nil {
 		return err
 	}
 
-	_, err = fmt.Fprintf(e.w, "%v%v\n", string(HeaderCSV), string(hdr))
+	err = e.validateTerms()
 	if err != nil {
 		return err
 	}
 
-	e.wcsv = csv.NewWriter(e.w)
-	e.wcsv.Comma = ';'
+	if e.Model == "" {
+		e.Model = DefaultModel
+	}
+
+	if e.model == nil {
+		e.model, err = NewModel(e.Model, e)
+		if err != nil {
+			return err
+		}
+	}
+
+	e.totA, e.totZ = e.totals()
+	if e.CheckEvery > 0 {
+		err = e.check(0)
+		if err != nil {
+			return err
+		}
+	}
+
+	if e.Population == nil {
+		e.Population = make([]Nucleus, len(Population))
+		copy(e.Population, Population)
+	}
+
+	if e.events != nil {
+		err = e.events.WriteHeader()
+		if err != nil {
+			return err
+		}
+	}
+
+	if e.out == nil {
+		return nil
+	}
+
+	err = e.out.WriteHeader(e.Metadata())
+	if err != nil {
+		return err
+	}
 
 	err = e.writeRecord()
 	if err != nil {
@@ -153,41 +294,626 @@ func (e *Engine) init(w io.Writer) error {
 	return err
 }
 
+// step performs at least one and at most n iterations of the simulation.
+func (e *Engine) step(n int) error {
+	if e.RejectionFree {
+		return e.processRF(n)
+	}
+	return e.process()
+}
+
+// process performs a single fusion attempt between two nuclei drawn
+// at random.
 func (e *Engine) process() error {
-	var err error
-	defer func() {
-		if err == nil {
-			err = e.writeRecord()
-		}
-	}()
+	e.iter++
+	e.acc.Attempts++
+
+	if len(e.nuclei) < 2 {
+		// no pair of nuclei to draw
+		e.acc.Self++
+		return e.endIter()
+	}
 
 	i := e.rng.Intn(len(e.nuclei))
 	j := e.rng.Intn(len(e.nuclei))
 	if i == j {
-		return err
+		e.acc.Self++
+		return e.endIter()
 	}
 	ni := e.nuclei[i]
 	nj := e.nuclei[j]
-	o, ok := Fuse(ni, nj)
+	flux := e.addFlux(ni, nj)
+	o, ok := e.model.Products(ni, nj)
 	if !ok {
 		// can't fuse nuclei
+		e.acc.NoFusion++
+		return e.endIter()
+	}
+	known := true
+	if tab, ok := e.model.(Tabulator); ok && !tab.Tabulated(ni, nj) {
+		e.fallbacks[Pair{ni, nj}.sorted()]++
+		known = false
+	}
+	xs, err := e.model.Probability(ni, nj, e.cond)
+	if err != nil {
 		return err
 	}
-	fuse := e.rng.Float64() < xsects[pair{ni, nj}]
-	switch fuse {
-	case true:
-		e.nuclei[i] = o
-		e.delete(j)
-	case false:
+	fuse := e.rng.Float64() < xs
+	if e.events != nil {
+		err = e.events.Write(Event{
+			Iter:     e.iter,
+			Pair:     Pair{ni, nj},
+			Product:  o,
+			Accepted: fuse,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	switch {
+	case fuse:
+		flux.Fusions++
+		e.fuse(i, j, o)
+	case known:
+		e.acc.Rejected++
+	default:
+		e.acc.Unknown++
+	}
+
+	return e.endIter()
+}
+
+// rfState holds the table of reactions and the number of remaining rejected
+// attempts of a rejection-free step.
+// The table is kept across fusions, captures, sources and sinks: only
+// the reactions of the species whose number changed are updated.
+type rfState struct {
+	species []Nucleus       // species of the table, in order of appearance
+	index   map[Nucleus]int // index of each species in the table
+	rates   [][]rfRate      // rates[i][j]: reaction of species i with species j
+	rows    []float64       // total weight of the reactions of each species
+	rrows   []float64       // total weight of the rejected draws of each species
+	total   float64         // total weight of all the reactions
+	rtotal  float64         // total weight of all the rejected draws of pairs
+	self    float64         // weight of the draws of the same nucleus twice
+	changed []Nucleus       // species whose number changed since the last update
+	tab     Tabulator
+	cond    Conditions // conditions at which the probabilities were computed
+	skip    int        // number of rejected attempts until the next fusion
+}
+
+// rfRate is an entry of the table of reactions of a rejection-free step.
+type rfRate struct {
+	ok    bool    // whether the pair has a fusion product
+	known bool    // whether the cross-section of the pair is tabulated
+	xs    float64 // fusion probability
+	draws float64 // number of ordered draws of the pair
+	w     float64 // weight of the reaction: draws*xs
+	rw    float64 // weight of the rejected draws: draws*(1-xs), or draws without fusion product
+}
+
+// processRF performs at most n iterations of a rejection-free step: the
+// rejected attempts preceding the next fusion, or that fusion, drawn
+// among all the possible reactions weighted by their probability.
+// The number of rejected attempts is the number of attempts a rejection
+// sampler would have needed to produce that fusion.
+// Rejected attempts are performed in bulk, up to the next iteration where
+// more than a record has to be written (see quietIters).
+func (e *Engine) processRF(n int) error {
+	if e.rf == nil {
+		rf, err := e.prepareRF()
+		if err != nil {
+			return err
+		}
+		e.rf = rf
+	}
+
+	rf := e.rf
+	if rf.skip > 0 {
+		k := rf.skip
+		if k > n {
+			k = n
+		}
+		if q := e.quietIters(); k > q+1 {
+			k = q + 1
+		}
+		if k > 1 {
+			// the population does not change during rejected attempts:
+			// the same record is written with the updated counters.
+			err := e.repeatRecords(k - 1)
+			if err != nil {
+				return err
+			}
+		}
+		rf.skip--
+		e.iter++
+		err := rf.reject(e)
+		if err != nil {
+			return err
+		}
+		err = e.endIter()
+		if err != nil {
+			return err
+		}
+		return e.refreshRF()
+	}
+
+	e.iter++
+	e.acc.Attempts++
+	ip, jp := rf.choose(e.rng.Float64()*rf.total, false)
+	ni, nj := rf.species[ip], rf.species[jp]
+	i := e.pick(ni, -1)
+	j := e.pick(nj, i)
+	o, _ := e.model.Products(ni, nj)
+	if rf.tab != nil && !rf.tab.Tabulated(ni, nj) {
+		e.fallbacks[Pair{ni, nj}.sorted()]++
+	}
+	e.addFlux(ni, nj).Fusions++
+	if e.events != nil {
+		err := e.events.Write(Event{
+			Iter:     e.iter,
+			Pair:     Pair{ni, nj},
+			Product:  o,
+			Accepted: true,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	e.fuse(i, j, o)
+
+	err := e.endIter()
+	if err != nil {
 		return err
 	}
+	return e.refreshRF()
+}
 
-	return err
+// refreshRF updates the reactions of the species whose number changed
+// during the last iteration and draws again the number of attempts until
+// the next fusion.
+// The whole table is computed again once the temperature drifted by more
+// than 1% from the one of its probabilities.
+func (e *Engine) refreshRF() error {
+	rf := e.rf
+	if math.Abs(e.cond.T9-rf.cond.T9) > 0.01*rf.cond.T9 {
+		e.rf = nil
+		return nil
+	}
+	if len(rf.changed) == 0 {
+		return nil
+	}
+	for _, n := range rf.changed {
+		err := rf.update(e, n)
+		if err != nil {
+			return err
+		}
+	}
+	rf.changed = rf.changed[:0]
+	rf.sum(e)
+	rf.draw(e)
+	return nil
+}
+
+// touch records that the number of nuclei of species n changed, so that
+// the reactions of n are updated before the next rejection-free step.
+func (e *Engine) touch(n Nucleus) {
+	if e.rf == nil {
+		return
+	}
+	for _, c := range e.rf.changed {
+		if c == n {
+			return
+		}
+	}
+	e.rf.changed = append(e.rf.changed, n)
+}
+
+// repeatRecords performs n rejected attempts of a rejection-free step,
+// writing the unchanged record of the population after each of them.
+func (e *Engine) repeatRecords(n int) error {
+	var (
+		rf     = e.rf
+		rec    = e.Record()
+		rep, _ = e.out.(RecordRepeater)
+	)
+	for k := 0; k < n; k++ {
+		rf.skip--
+		e.iter++
+		err := rf.reject(e)
+		if err != nil {
+			return err
+		}
+		switch {
+		case e.out == nil:
+			continue
+		case rep != nil:
+			err = rep.RepeatRecord(e.iter, e.acc)
+		default:
+			rec.Iter = e.iter
+			rec.Acceptance = e.acc
+			err = e.out.WriteRecord(rec)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quietIters returns the number of iterations following the current one
+// at the end of which only a record has to be written: no cooling,
+// electron capture, source or sink term, invariant check nor progress
+// message is due.
+func (e *Engine) quietIters() int {
+	if e.Capture.Enabled() || (e.Heating.Enabled() && e.Heating.Cooling != 0) {
+		return 0
+	}
+	next := e.NumIters
+	due := func(every int) {
+		if every <= 0 {
+			return
+		}
+		if v := (e.iter/every + 1) * every; v < next {
+			next = v
+		}
+	}
+	for _, src := range e.Sources {
+		due(src.Every)
+	}
+	for _, sink := range e.Sinks {
+		due(sink.Every)
+	}
+	due(e.CheckEvery)
+	due(e.NumIters / 10)
+	if next <= e.iter {
+		return 0
+	}
+	return next - e.iter - 1
+}
+
+// prepareRF computes the weights of all the possible reactions and
+// draws the number of rejected attempts until the next fusion.
+func (e *Engine) prepareRF() (*rfState, error) {
+	species := e.species()
+	rf := &rfState{
+		species: make([]Nucleus, 0, len(species)),
+		index:   make(map[Nucleus]int, len(species)),
+		cond:    e.cond,
+	}
+	rf.tab, _ = e.model.(Tabulator)
+	for _, n := range species {
+		err := rf.add(e, n)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, n := range species {
+		err := rf.update(e, n)
+		if err != nil {
+			return nil, err
+		}
+	}
+	rf.sum(e)
+	rf.draw(e)
+	return rf, nil
+}
+
+// add adds the species n to the table of reactions, with no draws.
+func (rf *rfState) add(e *Engine, n Nucleus) error {
+	rf.index[n] = len(rf.species)
+	rf.species = append(rf.species, n)
+	rf.rates = append(rf.rates, make([]rfRate, 0, len(rf.species)))
+	rf.rows = append(rf.rows, 0)
+	rf.rrows = append(rf.rrows, 0)
+	rate := func(ni, nj Nucleus) (rfRate, error) {
+		if _, ok := e.model.Products(ni, nj); !ok {
+			return rfRate{}, nil
+		}
+		xs, err := e.model.Probability(ni, nj, rf.cond)
+		if err != nil {
+			return rfRate{}, err
+		}
+		known := rf.tab == nil || rf.tab.Tabulated(ni, nj)
+		return rfRate{ok: true, known: known, xs: xs}, nil
+	}
+	last := len(rf.species) - 1
+	for i, ni := range rf.species {
+		r, err := rate(n, ni)
+		if err != nil {
+			return err
+		}
+		rf.rates[last] = append(rf.rates[last], r)
+		if i == last {
+			break
+		}
+		r, err = rate(ni, n)
+		if err != nil {
+			return err
+		}
+		rf.rates[i] = append(rf.rates[i], r)
+	}
+	return nil
+}
+
+// update updates the number of draws of the reactions of the species n,
+// adding n to the table if needed.
+func (rf *rfState) update(e *Engine, n Nucleus) error {
+	i, ok := rf.index[n]
+	if !ok {
+		err := rf.add(e, n)
+		if err != nil {
+			return err
+		}
+		i = rf.index[n]
+	}
+	ci := float64(e.counts[n])
+	for j, nj := range rf.species {
+		cj := float64(e.counts[nj])
+		if j == i {
+			rf.set(i, i, ci*(ci-1))
+			continue
+		}
+		rf.set(i, j, ci*cj)
+		rf.set(j, i, cj*ci)
+	}
+	// the weights of the row i are summed again, so that they are exactly
+	// zero once all the nuclei of species n are gone.
+	rf.rows[i] = 0
+	rf.rrows[i] = 0
+	for _, r := range rf.rates[i] {
+		rf.rows[i] += r.w
+		rf.rrows[i] += r.rw
+	}
+	return nil
+}
+
+// set sets the number of draws of the reaction (i, j) and updates the
+// weights of the table accordingly.
+func (rf *rfState) set(i, j int, draws float64) {
+	r := &rf.rates[i][j]
+	if draws == r.draws {
+		return
+	}
+	r.draws = draws
+	w, rw := 0.0, draws
+	if r.ok {
+		w = draws * r.xs
+		rw = draws * (1 - r.xs)
+	}
+	rf.rows[i] += w - r.w
+	rf.rrows[i] += rw - r.rw
+	r.w = w
+	r.rw = rw
+}
+
+// sum computes the total weights of the reactions, of the rejected draws
+// of pairs and of the self-draws.
+func (rf *rfState) sum(e *Engine) {
+	rf.total = 0
+	rf.rtotal = 0
+	for i := range rf.rows {
+		rf.total += rf.rows[i]
+		rf.rtotal += rf.rrows[i]
+	}
+	rf.self = float64(len(e.nuclei))
+}
+
+// choose returns the indices of the species of the reaction, or of
+// the rejected draw when rej is set, whose cumulative weight reaches u.
+func (rf *rfState) choose(u float64, rej bool) (int, int) {
+	rows := rf.rows
+	weight := func(r rfRate) float64 { return r.w }
+	if rej {
+		rows = rf.rrows
+		weight = func(r rfRate) float64 { return r.rw }
+	}
+	ip := len(rows) - 1
+	for i, row := range rows {
+		if u < row {
+			ip = i
+			break
+		}
+		u -= row
+	}
+	jp := -1
+	for j, r := range rf.rates[ip] {
+		w := weight(r)
+		if w <= 0 {
+			continue
+		}
+		jp = j
+		if u < w {
+			break
+		}
+		u -= w
+	}
+	if jp < 0 {
+		// rounding errors of the weights of the rows: fall back
+		// to the last possible draw.
+		for i := range rf.rates {
+			for j, r := range rf.rates[i] {
+				if weight(r) > 0 {
+					ip, jp = i, j
+				}
+			}
+		}
+	}
+	return ip, jp
+}
+
+// reject performs a rejected attempt of a rejection-free step.
+// The pair of nuclei drawn, or the draw of the same nucleus twice, is drawn
+// according to the weights of the rejections and recorded as a rejected
+// attempt of the standard sampling would be.
+func (rf *rfState) reject(e *Engine) error {
+	e.acc.Attempts++
+	u := e.arng.Float64() * (rf.self + rf.rtotal)
+	if u < rf.self || rf.rtotal <= 0 {
+		e.acc.Self++
+		return nil
+	}
+	i, j := rf.choose(u-rf.self, true)
+	r := rf.rates[i][j]
+	ni, nj := rf.species[i], rf.species[j]
+	e.addFlux(ni, nj)
+	if !r.ok {
+		e.acc.NoFusion++
+		return nil
+	}
+	if r.known {
+		e.acc.Rejected++
+	} else {
+		e.fallbacks[Pair{ni, nj}.sorted()]++
+		e.acc.Unknown++
+	}
+	if e.events == nil {
+		return nil
+	}
+	o, _ := e.model.Products(ni, nj)
+	return e.events.Write(Event{
+		Iter:     e.iter,
+		Pair:     Pair{ni, nj},
+		Product:  o,
+		Accepted: false,
+	})
+}
+
+// draw draws the number of attempts until the next fusion,
+// geometrically distributed.
+func (rf *rfState) draw(e *Engine) {
+	n := float64(len(e.nuclei))
+	q := 0.0
+	if n > 0 {
+		q = rf.total / (n * n)
+	}
+	rf.skip = e.NumIters - e.iter
+	switch {
+	case q >= 1:
+		rf.skip = 0
+	case q > 0:
+		v := math.Floor(math.Log(1-e.rng.Float64()) / math.Log1p(-q))
+		if v < float64(rf.skip) {
+			rf.skip = int(v)
+		}
+	}
+}
+
+// species returns the sorted list of nuclei species currently present.
+func (e *Engine) species() []Nucleus {
+	species := make([]Nucleus, 0, len(e.counts))
+	for n := range e.counts {
+		species = append(species, n)
+	}
+	sort.Slice(species, func(i, j int) bool {
+		return nucleusLess(species[i], species[j])
+	})
+	return species
+}
+
+// pick returns the index of a nucleus of species n drawn at random,
+// excluding the nucleus at index skip.
+func (e *Engine) pick(n Nucleus, skip int) int {
+	for {
+		i := e.rng.Intn(len(e.nuclei))
+		if i != skip && e.nuclei[i] == n {
+			return i
+		}
+	}
+}
+
+// fuse replaces the nucleus at index i with the fusion product o and
+// removes the nucleus at index j.
+func (e *Engine) fuse(i, j int, o Nucleus) {
+	e.decr(e.nuclei[i])
+	e.decr(e.nuclei[j])
+	e.counts[o]++
+	e.touch(e.nuclei[i])
+	e.touch(e.nuclei[j])
+	e.touch(o)
+	e.heat(e.nuclei[i], e.nuclei[j], o)
+	e.addLineage(i, j, o)
+	e.nuclei[i] = o
+	e.delete(j)
+}
+
+func (e *Engine) decr(n Nucleus) {
+	e.counts[n]--
+	if e.counts[n] == 0 {
+		delete(e.counts, n)
+	}
+}
+
+// endIter writes the record of the current iteration and
+// performs the periodic bookkeeping of the simulation.
+func (e *Engine) endIter() error {
+	e.cool()
+	e.capture()
+	err := e.applyTerms()
+	if err != nil {
+		return err
+	}
+	err = e.writeRecord()
+	if err != nil {
+		return err
+	}
+	if e.CheckEvery > 0 && (e.iter%e.CheckEvery == 0 || e.iter == e.NumIters) {
+		err = e.check(e.iter)
+		if err != nil {
+			return err
+		}
+	}
+	if n := e.NumIters / 10; n > 0 && e.iter%n == 0 {
+		e.logger.Printf("iter #%d/%d...\n", e.iter, e.NumIters)
+	}
+	return nil
+}
+
+// Counts returns the number of nuclei of each species in the population.
+func (e *Engine) Counts() map[Nucleus]int {
+	counts := make(map[Nucleus]int, len(e.counts))
+	for n, c := range e.counts {
+		counts[n] = c
+	}
+	return counts
+}
+
+// Fallbacks returns the pairs of nuclei without tabulated cross-section
+// drawn during the last run, with the number of times each was drawn.
+// The fusion probability of these pairs was given by the Fallback model.
+// Fallbacks is only filled for fusion models implementing Tabulator.
+func (e *Engine) Fallbacks() map[Pair]int {
+	o := make(map[Pair]int, len(e.fallbacks))
+	for k, v := range e.fallbacks {
+		o[k] = v
+	}
+	return o
+}
+
+func (e *Engine) fallbackReport() string {
+	pairs := make([]Pair, 0, len(e.fallbacks))
+	for p := range e.fallbacks {
+		pairs = append(pairs, p)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairLess(pairs[i], pairs[j])
+	})
+
+	o := []string{}
+	o = append(o, fmt.Sprintf("pairs without cross-section (model=%s, fallback=%v):", e.Model, e.Fallback.Mode))
+	for _, p := range pairs {
+		o = append(o, fmt.Sprintf("%v: %d", p, e.fallbacks[p]))
+	}
+	return strings.Join(o, "\n")
 }
 
 func (e *Engine) delete(i int) {
 	e.nuclei[i] = e.nuclei[len(e.nuclei)-1]
 	e.nuclei = e.nuclei[:len(e.nuclei)-1]
+	if e.ids != nil {
+		e.ids[i] = e.ids[len(e.ids)-1]
+		e.ids = e.ids[:len(e.ids)-1]
+	}
 }
 
 func (e *Engine) stats() stats {
@@ -212,12 +938,10 @@ func (e *Engine) stats() stats {
 }
 
 func (e *Engine) writeRecord() error {
-	data := make([]string, len(e.Population))
-	stats := e.stats()
-	for i, n := range e.Population {
-		data[i] = itoa(stats.histo[n] * n.A)
+	if e.out == nil {
+		return nil
 	}
-	return e.wcsv.Write(data)
+	return e.out.WriteRecord(e.Record())
 }
 
 type stats struct {
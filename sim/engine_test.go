@@ -0,0 +1,112 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+// yields runs the simulations returned by newEngine for the seeds 1 to n,
+// and returns the mean and the standard error of the mean of the final mass
+// fraction of each species, and the mean fraction of rejected attempts.
+func yields(t *testing.T, n int, newEngine func(seed int64) *Engine) (mean, sem map[Nucleus]float64, rej float64) {
+	t.Helper()
+	sum := make(map[Nucleus]float64)
+	sum2 := make(map[Nucleus]float64)
+	for seed := int64(1); seed <= int64(n); seed++ {
+		e := newEngine(seed)
+		err := e.Start()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for e.Next() {
+		}
+		if err := e.Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		acc := e.AcceptanceStats()
+		attempts, fusions := 0, 0
+		for _, f := range e.Flux() {
+			attempts += f.Attempts
+			fusions += f.Fusions
+		}
+		if got, want := attempts, acc.Attempts-acc.Self; got != want {
+			t.Errorf("seed=%d: invalid number of attempts in the reaction flux: got=%d, want=%d", seed, got, want)
+		}
+		if got, want := fusions, acc.Fusions(); got != want {
+			t.Errorf("seed=%d: invalid number of fusions in the reaction flux: got=%d, want=%d", seed, got, want)
+		}
+		rej += float64(acc.Rejected+acc.Unknown) / float64(acc.Attempts) / float64(n)
+
+		tot := float64(e.totA)
+		for nuc, c := range e.Counts() {
+			x := float64(c*nuc.A) / tot
+			sum[nuc] += x
+			sum2[nuc] += x * x
+		}
+	}
+	mean = make(map[Nucleus]float64, len(sum))
+	sem = make(map[Nucleus]float64, len(sum))
+	for nuc, s := range sum {
+		m := s / float64(n)
+		mean[nuc] = m
+		sem[nuc] = math.Sqrt(math.Max(0, sum2[nuc]/float64(n)-m*m) / float64(n-1))
+	}
+	return mean, sem, rej
+}
+
+func TestRejectionFreeYields(t *testing.T) {
+	const nseeds = 8
+	for _, tc := range []struct {
+		name string
+		e    Engine
+	}{
+		{
+			name: "default",
+			e:    Engine{NumIters: 20000, NumCarbons: 60},
+		},
+		{
+			name: "capture-source",
+			e: Engine{
+				NumIters:   20000,
+				NumCarbons: 60,
+				Conditions: Conditions{T9: 3, Rho: 1e9},
+				Capture:    Capture{Rate: 1},
+				Sources:    []Source{{Every: 1000, N: 200}},
+				Sinks:      []Sink{{Every: 2500, Fraction: 0.05}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			run := func(rejectionFree bool) func(seed int64) *Engine {
+				return func(seed int64) *Engine {
+					e := tc.e
+					e.Seed = seed
+					e.RejectionFree = rejectionFree
+					return &e
+				}
+			}
+			m1, s1, r1 := yields(t, nseeds, run(false))
+			m2, s2, r2 := yields(t, nseeds, run(true))
+
+			species := make(map[Nucleus]bool)
+			for n := range m1 {
+				species[n] = true
+			}
+			for n := range m2 {
+				species[n] = true
+			}
+			for n := range species {
+				diff := math.Abs(m1[n] - m2[n])
+				tol := 4*math.Hypot(s1[n], s2[n]) + 2e-3
+				if diff > tol {
+					t.Errorf("%v: invalid mean mass fraction: standard=%.4f±%.4f, rejection-free=%.4f±%.4f",
+						n, m1[n], s1[n], m2[n], s2[n])
+				}
+			}
+			if math.Abs(r1-r2) > 0.01 {
+				t.Errorf("invalid fraction of rejected attempts: standard=%.4f, rejection-free=%.4f", r1, r2)
+			}
+		})
+	}
+}
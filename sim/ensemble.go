@@ -0,0 +1,89 @@
+package sim
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// tableSalt separates the stream of the seeds of the sampled tables of
+// cross-sections from the seeds of the simulations.
+const tableSalt = 0x5a17ab1e
+
+// Yield is the final mass of a nucleus over an ensemble of simulations.
+type Yield struct {
+	Nucleus Nucleus
+	Mean    float64 // mean final mass
+	Std     float64 // standard deviation of the final mass
+}
+
+// Ensemble describes an ensemble of simulations differing by their seeds
+// (Engine.Seed, Engine.Seed+1, ...) and, when Table is set, by their
+// cross-sections: each member samples a new table of cross-sections, so
+// the spread of the yields includes the nuclear-physics uncertainties.
+// Tables are sampled with seeds drawn from a stream of their own, so that
+// they are independent of the initial compositions and of the pair draws
+// of the simulations.
+// Sampled tables holding cross-sections above 1, which fusion attempts
+// would clip, are normalized (see normalize).
+type Ensemble struct {
+	Engine  Engine // configuration of the simulations
+	Members int    // number of simulations (zero: 10)
+	Table   Table  // uncertain cross-sections of the Standard fusion model (zero: exact cross-sections)
+}
+
+// Run runs the simulations of the ensemble and returns the yields of
+// the nuclei of the population.
+func (ens Ensemble) Run() ([]Yield, error) {
+	if ens.Members == 0 {
+		ens.Members = 10
+	}
+	if ens.Table != nil && ens.Engine.Model != "" && ens.Engine.Model != DefaultModel {
+		return nil, fmt.Errorf("sim: uncertain cross-sections need the %q fusion model", DefaultModel)
+	}
+
+	pop := ens.Engine.Population
+	if pop == nil {
+		pop = Population
+	}
+
+	tseeds := make([]int64, ens.Members)
+	trng := rand.New(rand.NewSource(ens.Engine.Seed ^ tableSalt))
+	for k := range tseeds {
+		tseeds[k] = trng.Int63()
+	}
+
+	masses := make([][]float64, ens.Members)
+	err := parallel(ens.Members, func(k int) error {
+		seed := ens.Engine.Seed + int64(k)
+		tmpl := ens.Engine
+		var table map[Pair]float64
+		if ens.Table != nil {
+			var norm float64
+			table, norm = normalize(ens.Table.Sample(rand.New(rand.NewSource(tseeds[k]))))
+			stretch(&tmpl, norm)
+		}
+		counts, err := runMember(tmpl, table, seed)
+		if err != nil {
+			return err
+		}
+		masses[k] = make([]float64, len(pop))
+		for i, n := range pop {
+			masses[k][i] = float64(counts[n] * n.A)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	yields := make([]Yield, len(pop))
+	vs := make([]float64, ens.Members)
+	for i, n := range pop {
+		for k := range masses {
+			vs[k] = masses[k][i]
+		}
+		mean, std := meanStd(vs)
+		yields[i] = Yield{Nucleus: n, Mean: mean, Std: std}
+	}
+	return yields, nil
+}
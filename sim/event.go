@@ -0,0 +1,168 @@
+package sim
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// HeaderEvents identifies the start of an event log.
+var HeaderEvents = []byte("# snfusion-events=iter;A1;Z1;A2;Z2;A;Z;accepted")
+
+// Event describes a fusion attempt between two nuclei.
+type Event struct {
+	Iter     int     // iteration of the attempt
+	Pair     Pair    // reacting nuclei
+	Product  Nucleus // fusion product
+	Accepted bool    // whether the fusion happened
+}
+
+func (evt Event) String() string {
+	status := "rejected"
+	if evt.Accepted {
+		status = "accepted"
+	}
+	return fmt.Sprintf("#%d: %v -> %v (%s)", evt.Iter, evt.Pair, evt.Product, status)
+}
+
+// EventWriter writes an event log.
+// Events are written one per line, as ';' separated values:
+//
+//	iter;A1;Z1;A2;Z2;A;Z;accepted
+//
+// where accepted is 1 for fusions that happened, 0 otherwise.
+// The events are preceded by a HeaderEvents line.
+type EventWriter struct {
+	w    io.Writer
+	wcsv *csv.Writer
+	hdr  bool
+	data []string
+}
+
+// NewEventWriter returns a new EventWriter writing to w.
+func NewEventWriter(w io.Writer) *EventWriter {
+	wcsv := csv.NewWriter(w)
+	wcsv.Comma = ';'
+	return &EventWriter{
+		w:    w,
+		wcsv: wcsv,
+		data: make([]string, 8),
+	}
+}
+
+// WriteHeader writes the header of the event log, if not already written.
+func (ew *EventWriter) WriteHeader() error {
+	if ew.hdr {
+		return nil
+	}
+	ew.hdr = true
+	_, err := fmt.Fprintf(ew.w, "%s\n", HeaderEvents)
+	return err
+}
+
+// Write writes a single event.
+func (ew *EventWriter) Write(evt Event) error {
+	err := ew.WriteHeader()
+	if err != nil {
+		return err
+	}
+	ew.data[0] = itoa(evt.Iter)
+	ew.data[1] = itoa(evt.Pair[0].A)
+	ew.data[2] = itoa(evt.Pair[0].Z)
+	ew.data[3] = itoa(evt.Pair[1].A)
+	ew.data[4] = itoa(evt.Pair[1].Z)
+	ew.data[5] = itoa(evt.Product.A)
+	ew.data[6] = itoa(evt.Product.Z)
+	ew.data[7] = "0"
+	if evt.Accepted {
+		ew.data[7] = "1"
+	}
+	return ew.wcsv.Write(ew.data)
+}
+
+// Flush writes any buffered event to the underlying io.Writer.
+func (ew *EventWriter) Flush() error {
+	ew.wcsv.Flush()
+	return ew.wcsv.Error()
+}
+
+// EventReader reads an event log written by an EventWriter.
+type EventReader struct {
+	br  *bufio.Reader
+	r   *csv.Reader
+	hdr bool
+}
+
+// NewEventReader returns a new EventReader reading from r.
+func NewEventReader(r io.Reader) *EventReader {
+	br := bufio.NewReader(r)
+	rcsv := csv.NewReader(br)
+	rcsv.Comma = ';'
+	rcsv.Comment = '#'
+	rcsv.FieldsPerRecord = 8
+	rcsv.ReuseRecord = true
+	return &EventReader{br: br, r: rcsv}
+}
+
+// readHeader reads and checks the header of the event log.
+func (er *EventReader) readHeader() error {
+	er.hdr = true
+	line, err := er.br.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if len(line) == 0 {
+		return fmt.Errorf("sim: missing event log header")
+	}
+	if !bytes.Equal(bytes.TrimRight(line, "\r\n"), HeaderEvents) {
+		return fmt.Errorf("sim: invalid event log header %q", line)
+	}
+	return nil
+}
+
+// Read reads the next event.
+// Read returns io.EOF when no more events are available.
+func (er *EventReader) Read() (Event, error) {
+	var evt Event
+	if !er.hdr {
+		err := er.readHeader()
+		if err != nil {
+			return evt, err
+		}
+	}
+	rec, err := er.r.Read()
+	if err != nil {
+		return evt, err
+	}
+
+	var v [8]int
+	for i, s := range rec {
+		v[i], err = strconv.Atoi(s)
+		if err != nil {
+			return evt, fmt.Errorf("sim: invalid event field %q: %v", s, err)
+		}
+	}
+	evt.Iter = v[0]
+	evt.Pair = Pair{{A: v[1], Z: v[2]}, {A: v[3], Z: v[4]}}
+	evt.Product = Nucleus{A: v[5], Z: v[6]}
+	evt.Accepted = v[7] != 0
+	return evt, nil
+}
+
+// ReadAll reads all the remaining events.
+func (er *EventReader) ReadAll() ([]Event, error) {
+	var evts []Event
+	for {
+		evt, err := er.Read()
+		if err == io.EOF {
+			return evts, nil
+		}
+		if err != nil {
+			return evts, err
+		}
+		evts = append(evts, evt)
+	}
+}
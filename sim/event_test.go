@@ -0,0 +1,84 @@
+package sim
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEventLog(t *testing.T) {
+	for _, n := range []int{0, 200} {
+		var buf bytes.Buffer
+		e := Engine{NumIters: n, NumCarbons: 60, Seed: 1234}
+		e.SetEventLog(&buf)
+		err := e.Run(ioutil.Discard)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(buf.Bytes(), append(HeaderEvents, '\n')) {
+			t.Fatalf("n=%d: missing event log header:\n%s", n, buf.Bytes())
+		}
+
+		evts, err := NewEventReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("n=%d: could not read event log: %v", n, err)
+		}
+		if got, want := len(evts), e.AcceptanceStats().Attempts-e.AcceptanceStats().Self; got != want {
+			t.Fatalf("n=%d: invalid number of events: got=%d, want=%d", n, got, want)
+		}
+		for i, evt := range evts {
+			if evt.Iter < 1 || evt.Iter > n {
+				t.Fatalf("n=%d: invalid iteration of event %d: %v", n, i, evt)
+			}
+		}
+	}
+}
+
+func TestEventReader(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data string
+		want []Event
+		err  string
+	}{
+		{
+			name: "empty",
+			err:  "sim: missing event log header",
+		},
+		{
+			name: "no-header",
+			data: "1;12;6;12;6;24;12;1\n",
+			err:  `sim: invalid event log header "1;12;6;12;6;24;12;1\n"`,
+		},
+		{
+			name: "header-only",
+			data: string(HeaderEvents) + "\n",
+		},
+		{
+			name: "events",
+			data: string(HeaderEvents) + "\n1;12;6;12;6;24;12;1\n2;12;6;16;8;28;14;0\n",
+			want: []Event{
+				{Iter: 1, Pair: Pair{nC, nC}, Product: nMg, Accepted: true},
+				{Iter: 2, Pair: Pair{nC, nO}, Product: nSi},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			evts, err := NewEventReader(strings.NewReader(tc.data)).ReadAll()
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("invalid error:\ngot= %v\nwant=%v", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(evts, tc.want) {
+				t.Fatalf("invalid events:\ngot= %v\nwant=%v", evts, tc.want)
+			}
+		})
+	}
+}
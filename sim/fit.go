@@ -0,0 +1,237 @@
+package sim
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Observation is a measured yield of a nucleus.
+// Yields are only compared relative to each other, so they may be given
+// as masses, mass fractions or abundance ratios to a common reference.
+type Observation struct {
+	Nucleus Nucleus
+	Value   float64
+	Err     float64 // uncertainty of the measurement
+}
+
+// ReadObservations reads a list of observed yields from r.
+// Each line holds the name of a nucleus, its yield and the uncertainty of
+// the yield, separated by spaces. Empty lines and lines starting with '#'
+// are ignored.
+func ReadObservations(r io.Reader) ([]Observation, error) {
+	var obs []Observation
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		txt := strings.TrimSpace(scanner.Text())
+		if txt == "" || strings.HasPrefix(txt, "#") {
+			continue
+		}
+		toks := strings.Fields(txt)
+		if len(toks) != 3 {
+			return nil, fmt.Errorf("sim: line %d: expected nucleus, yield and uncertainty", line)
+		}
+		n, err := ParseNucleus(toks[0])
+		if err != nil {
+			return nil, fmt.Errorf("sim: line %d: %v", line, err)
+		}
+		v, err := strconv.ParseFloat(toks[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("sim: line %d: invalid yield: %v", line, err)
+		}
+		e, err := strconv.ParseFloat(toks[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("sim: line %d: invalid uncertainty: %v", line, err)
+		}
+		if e <= 0 {
+			return nil, fmt.Errorf("sim: line %d: uncertainty must be positive", line)
+		}
+		obs = append(obs, Observation{Nucleus: n, Value: v, Err: e})
+	}
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+	if len(obs) < 2 {
+		return nil, fmt.Errorf("sim: at least 2 observed yields are needed")
+	}
+	return obs, nil
+}
+
+// Fit describes the fit of the initial composition (and, optionally, of the
+// number of iterations) of a simulation to observed yields.
+//
+// Each point of the grid is simulated by an Ensemble, and compared to
+// the observations by
+//
+//	χ2 = Σ (f - fobs)^2 / (σobs^2 + σ^2)
+//
+// where f and fobs are the simulated and observed yields, normalized to
+// the sum of the observed species, and σ the Monte Carlo uncertainty
+// of the mean simulated yield.
+type Fit struct {
+	Engine       Engine // configuration of the simulations
+	Members      int    // number of simulations per grid point (zero: 10)
+	Observations []Observation
+	Carbons      []float64 // grid of carbon ratios
+	Iters        []int     // grid of numbers of iterations (zero: Engine.NumIters)
+}
+
+// FitPoint is the goodness of fit of a grid point.
+type FitPoint struct {
+	NumCarbons float64
+	NumIters   int
+	Chi2       float64
+}
+
+// FitResult is the result of a Fit.
+type FitResult struct {
+	Points []FitPoint // all grid points
+	Best   FitPoint   // grid point with the lowest χ2
+	NDF    int        // number of degrees of freedom
+
+	// CarbonsLo and CarbonsHi bound the 68% confidence interval of
+	// the carbon ratio, where the χ2 profile is within 1 of its minimum.
+	// A bound is infinite when the χ2 profile does not exceed that limit
+	// on its side of the best fit: it reaches the edge of the grid, or
+	// a grid point where the χ2 is undefined.
+	CarbonsLo, CarbonsHi float64
+}
+
+// Run runs the simulations of all the grid points and returns the best fit.
+// The normalization of the yields and each fitted parameter (the carbon
+// ratio and, with several Iters, the number of iterations) use one degree
+// of freedom: Run needs at least one more observation than these.
+func (f Fit) Run() (*FitResult, error) {
+	if len(f.Carbons) == 0 {
+		return nil, fmt.Errorf("sim: empty grid of carbon ratios")
+	}
+	iters := f.Iters
+	if len(iters) == 0 {
+		iters = []int{f.Engine.NumIters}
+	}
+
+	// observations are normalized: one degree of freedom is lost.
+	nparams := 1
+	if len(iters) > 1 {
+		nparams++
+	}
+	ndf := len(f.Observations) - 1 - nparams
+	if ndf < 1 {
+		return nil, fmt.Errorf(
+			"sim: not enough observed yields: got %d, want at least %d",
+			len(f.Observations), nparams+2,
+		)
+	}
+
+	pop := make([]Nucleus, len(f.Observations))
+	var tot float64
+	for i, o := range f.Observations {
+		pop[i] = o.Nucleus
+		tot += o.Value
+	}
+	if tot <= 0 {
+		return nil, fmt.Errorf("sim: observed yields must not sum up to zero")
+	}
+
+	carbons := make([]float64, len(f.Carbons))
+	copy(carbons, f.Carbons)
+	sort.Float64s(carbons)
+
+	res := &FitResult{NDF: ndf}
+	for _, c := range carbons {
+		for _, n := range iters {
+			ens := Ensemble{Engine: f.Engine, Members: f.Members}
+			ens.Engine.NumCarbons = c
+			ens.Engine.NumIters = n
+			ens.Engine.Population = pop
+			yields, err := ens.Run()
+			if err != nil {
+				return nil, err
+			}
+			members := ens.Members
+			if members == 0 {
+				members = 10
+			}
+
+			var sum float64
+			for _, y := range yields {
+				sum += y.Mean
+			}
+			chi2 := math.Inf(+1)
+			if sum > 0 {
+				chi2 = 0
+				for i, y := range yields {
+					obs := f.Observations[i]
+					d := y.Mean/sum - obs.Value/tot
+					serr := y.Std / sum / math.Sqrt(float64(members))
+					oerr := obs.Err / tot
+					chi2 += d * d / (oerr*oerr + serr*serr)
+				}
+			}
+			pt := FitPoint{NumCarbons: c, NumIters: n, Chi2: chi2}
+			res.Points = append(res.Points, pt)
+			if len(res.Points) == 1 || pt.Chi2 < res.Best.Chi2 {
+				res.Best = pt
+			}
+		}
+	}
+
+	res.CarbonsLo, res.CarbonsHi = res.interval(carbons)
+	return res, nil
+}
+
+// interval returns the range of carbon ratios where the χ2 profile,
+// minimized over the numbers of iterations, is within 1 of its minimum.
+// Bounds are linearly interpolated between the sorted grid points, and
+// are infinite when the profile does not cross the limit.
+func (res *FitResult) interval(carbons []float64) (lo, hi float64) {
+	profile := make([]float64, len(carbons))
+	for i, c := range carbons {
+		profile[i] = math.Inf(+1)
+		for _, pt := range res.Points {
+			if pt.NumCarbons == c && pt.Chi2 < profile[i] {
+				profile[i] = pt.Chi2
+			}
+		}
+	}
+
+	ibest := 0
+	for i := range profile {
+		if profile[i] < profile[ibest] {
+			ibest = i
+		}
+	}
+	lim := profile[ibest] + 1
+
+	// cross interpolates the crossing of lim between the grid points i and j,
+	// or returns the open bound when the χ2 is undefined at j.
+	cross := func(i, j int, open float64) float64 {
+		if math.IsInf(profile[j], +1) {
+			return open
+		}
+		t := (lim - profile[i]) / (profile[j] - profile[i])
+		return carbons[i] + t*(carbons[j]-carbons[i])
+	}
+
+	lo, hi = math.Inf(-1), math.Inf(+1)
+	for i := ibest; i > 0; i-- {
+		if profile[i-1] > lim {
+			lo = cross(i, i-1, lo)
+			break
+		}
+	}
+	for i := ibest; i < len(profile)-1; i++ {
+		if profile[i+1] > lim {
+			hi = cross(i, i+1, hi)
+			break
+		}
+	}
+	return lo, hi
+}
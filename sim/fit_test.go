@@ -0,0 +1,99 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFitInterval(t *testing.T) {
+	inf := math.Inf(+1)
+	carbons := []float64{10, 20, 30, 40, 50}
+	for _, tc := range []struct {
+		name   string
+		chi2   [][2]float64 // χ2 of each carbon ratio, for 2 numbers of iterations
+		lo, hi float64
+	}{
+		{
+			name: "closed",
+			chi2: [][2]float64{{inf, inf}, {3, 5}, {0.5, 2}, {1, 7}, {4, 4}},
+			lo:   26,
+			hi:   40 + 10.0/6,
+		},
+		{
+			name: "undefined-neighbour",
+			chi2: [][2]float64{{9, 9}, {3, 9}, {0.5, 9}, {inf, inf}, {2, 9}},
+			lo:   26,
+			hi:   inf,
+		},
+		{
+			name: "grid-edges",
+			chi2: [][2]float64{{inf, 1}, {2, 1.2}, {0.5, 2}, {1, 1}, {1.4, 8}},
+			lo:   math.Inf(-1),
+			hi:   inf,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var res FitResult
+			for i, c := range carbons {
+				for k, chi2 := range tc.chi2[i] {
+					res.Points = append(res.Points, FitPoint{
+						NumCarbons: c,
+						NumIters:   1000 * (k + 1),
+						Chi2:       chi2,
+					})
+				}
+			}
+			lo, hi := res.interval(carbons)
+			if math.Abs(lo-tc.lo) > 1e-9 && lo != tc.lo {
+				t.Errorf("invalid lower bound: got=%v, want=%v", lo, tc.lo)
+			}
+			if math.Abs(hi-tc.hi) > 1e-9 && hi != tc.hi {
+				t.Errorf("invalid upper bound: got=%v, want=%v", hi, tc.hi)
+			}
+		})
+	}
+}
+
+func TestFitNDF(t *testing.T) {
+	obs := []Observation{
+		{Nucleus: nC, Value: 1, Err: 0.1},
+		{Nucleus: nO, Value: 1, Err: 0.1},
+		{Nucleus: nMg, Value: 1, Err: 0.1},
+		{Nucleus: nSi, Value: 1, Err: 0.1},
+	}
+	for _, tc := range []struct {
+		name  string
+		nobs  int
+		iters []int
+		ndf   int
+		err   string
+	}{
+		{name: "2-obs", nobs: 2, err: "sim: not enough observed yields: got 2, want at least 3"},
+		{name: "3-obs", nobs: 3, ndf: 1},
+		{name: "3-obs-iters", nobs: 3, iters: []int{10, 20}, err: "sim: not enough observed yields: got 3, want at least 4"},
+		{name: "4-obs-iters", nobs: 4, iters: []int{10, 20}, ndf: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fit := Fit{
+				Engine:       Engine{NumIters: 10, Seed: 1234},
+				Members:      2,
+				Observations: obs[:tc.nobs],
+				Carbons:      []float64{50},
+				Iters:        tc.iters,
+			}
+			res, err := fit.Run()
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("invalid error:\ngot= %v\nwant=%v", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.NDF != tc.ndf {
+				t.Fatalf("invalid NDF: got=%d, want=%d", res.NDF, tc.ndf)
+			}
+		})
+	}
+}
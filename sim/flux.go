@@ -0,0 +1,113 @@
+package sim
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// HeaderFlux identifies the start of the reaction flux trailer.
+var HeaderFlux = []byte("# snfusion-flux=")
+
+// Flux holds the number of fusion attempts and fusions of a pair of nuclei.
+type Flux struct {
+	Pair     Pair
+	Attempts int // number of times the pair was drawn
+	Fusions  int // number of times the pair fused
+}
+
+// ReactionFlux is the integrated reaction flux of a run,
+// sorted by increasing mass numbers of the reacting nuclei.
+type ReactionFlux []Flux
+
+// Lookup returns the flux of the pair of nuclei ni and nj.
+func (rf ReactionFlux) Lookup(ni, nj Nucleus) Flux {
+	p := Pair{ni, nj}.sorted()
+	for _, f := range rf {
+		if f.Pair == p {
+			return f
+		}
+	}
+	return Flux{Pair: p}
+}
+
+// Matrix returns the number of fusion attempts and of fusions between
+// all pairs of the provided nuclei.
+// The returned matrices are symmetric.
+func (rf ReactionFlux) Matrix(nuclei []Nucleus) (attempts, fusions [][]int) {
+	idx := make(map[Nucleus]int, len(nuclei))
+	for i, n := range nuclei {
+		idx[n] = i
+	}
+	attempts = make([][]int, len(nuclei))
+	fusions = make([][]int, len(nuclei))
+	for i := range nuclei {
+		attempts[i] = make([]int, len(nuclei))
+		fusions[i] = make([]int, len(nuclei))
+	}
+	for _, f := range rf {
+		i, ok := idx[f.Pair[0]]
+		if !ok {
+			continue
+		}
+		j, ok := idx[f.Pair[1]]
+		if !ok {
+			continue
+		}
+		attempts[i][j] = f.Attempts
+		attempts[j][i] = f.Attempts
+		fusions[i][j] = f.Fusions
+		fusions[j][i] = f.Fusions
+	}
+	return attempts, fusions
+}
+
+// ReadFlux reads the reaction flux trailer of a file created by an Engine.
+func ReadFlux(r io.Reader) (ReactionFlux, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		data := scanner.Bytes()
+		if !bytes.HasPrefix(data, HeaderFlux) {
+			continue
+		}
+		var rf ReactionFlux
+		err := json.Unmarshal(data[len(HeaderFlux):], &rf)
+		if err != nil {
+			return nil, err
+		}
+		return rf, nil
+	}
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("sim: no reaction flux trailer")
+}
+
+// Flux returns the integrated reaction flux of the last run.
+func (e *Engine) Flux() ReactionFlux {
+	rf := make(ReactionFlux, 0, len(e.flux))
+	for _, f := range e.flux {
+		rf = append(rf, *f)
+	}
+	sort.Slice(rf, func(i, j int) bool {
+		return pairLess(rf[i].Pair, rf[j].Pair)
+	})
+	return rf
+}
+
+// addFlux records a fusion attempt between the nuclei ni and nj.
+func (e *Engine) addFlux(ni, nj Nucleus) *Flux {
+	p := Pair{ni, nj}.sorted()
+	f, ok := e.flux[p]
+	if !ok {
+		f = &Flux{Pair: p}
+		e.flux[p] = f
+	}
+	f.Attempts++
+	return f
+}
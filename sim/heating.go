@@ -0,0 +1,108 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+)
+
+// minT9 is the lowest temperature, in GK, a self-heating simulation
+// may reach.
+const minT9 = 1e-3
+
+// Heating describes the thermal evolution of a self-heating simulation.
+//
+// The temperature starts at Conditions.T9, which must be positive.
+// Each fusion releasing an energy Q raises the temperature by
+// Q/HeatCapacity, and the temperature relaxes towards its initial value
+// at each iteration:
+//
+//	T9 -= Cooling * (T9 - Conditions.T9)
+//
+// Fusion probabilities are scaled by the ratio of the Gamow factors of
+// the fusing nuclei at the current temperature and at RefT9, the
+// temperature at which the tabulated cross-sections hold.
+type Heating struct {
+	HeatCapacity float64 // heat capacity, in MeV/GK (zero: no self-heating)
+	Cooling      float64 // relaxation rate per iteration
+	RefT9        float64 // temperature of the tabulated cross-sections, in GK (zero: Conditions.T9)
+}
+
+// Enabled returns whether self-heating is enabled.
+func (h Heating) Enabled() bool {
+	return h.HeatCapacity > 0
+}
+
+// validate checks that the temperatures needed by self-heating, starting
+// at the conditions c, are set.
+func (h Heating) validate(c Conditions) error {
+	if !h.Enabled() {
+		return nil
+	}
+	if c.T9 <= 0 {
+		return fmt.Errorf("sim: self-heating needs a positive initial temperature (T9=%g)", c.T9)
+	}
+	if h.RefT9 < 0 {
+		return fmt.Errorf("sim: invalid reference temperature RefT9=%g", h.RefT9)
+	}
+	return nil
+}
+
+// Temperature returns the current temperature of the simulation, in GK.
+func (e *Engine) Temperature() float64 {
+	return e.cond.T9
+}
+
+// Energy returns the total energy released by fusions during the last run,
+// in MeV.
+// The released energy is only accounted for when self-heating is enabled.
+func (e *Engine) Energy() float64 {
+	return e.energy
+}
+
+// refT9 returns the temperature at which the fusion probabilities of
+// the model hold, in GK.
+func (e *Engine) refT9() float64 {
+	if e.Heating.RefT9 == 0 {
+		return e.Conditions.T9
+	}
+	return e.Heating.RefT9
+}
+
+// gamow returns the exponent of the Gamow factor of the nuclei
+// n1 and n2 at the temperature t9, in GK.
+func gamow(n1, n2 Nucleus, t9 float64) float64 {
+	a1 := float64(n1.A)
+	a2 := float64(n2.A)
+	mu := a1 * a2 / (a1 + a2)
+	z := float64(n1.Z * n2.Z)
+	return 4.2487 * math.Cbrt(z*z*mu/t9)
+}
+
+// scaleT9 scales the fusion probability p of the nuclei n1 and n2,
+// valid at the temperature ref, to the temperature t9.
+func scaleT9(p float64, n1, n2 Nucleus, ref, t9 float64) float64 {
+	if ref <= 0 || t9 <= 0 || t9 == ref {
+		return p
+	}
+	p *= math.Exp(gamow(n1, n2, ref) - gamow(n1, n2, t9))
+	return math.Min(1, p)
+}
+
+// heat releases the energy of the fusion of the nuclei n1 and n2 into o.
+func (e *Engine) heat(n1, n2, o Nucleus) {
+	if !e.Heating.Enabled() {
+		return
+	}
+	q := QValue(n1, n2, o)
+	e.energy += q
+	e.cond.T9 = math.Max(minT9, e.cond.T9+q/e.Heating.HeatCapacity)
+}
+
+// cool relaxes the temperature towards its initial value.
+func (e *Engine) cool() {
+	if !e.Heating.Enabled() {
+		return
+	}
+	e.cond.T9 -= e.Heating.Cooling * (e.cond.T9 - e.Conditions.T9)
+	e.cond.T9 = math.Max(minT9, e.cond.T9)
+}
@@ -0,0 +1,53 @@
+package sim
+
+import (
+	"fmt"
+)
+
+// InvariantError reports a violation of the invariants of a simulation:
+// conservation of the total mass and charge, and nuclei lying within
+// the allowed region of the chart of nuclides.
+type InvariantError struct {
+	Iter int    // iteration at which the violation was detected
+	Msg  string // description of the violation
+}
+
+func (err *InvariantError) Error() string {
+	return fmt.Sprintf("sim: invariant violated at iteration %d: %s", err.Iter, err.Msg)
+}
+
+// totals returns the total mass number and atomic number of the
+// population of nuclei.
+func (e *Engine) totals() (a, z int) {
+	for _, n := range e.nuclei {
+		a += n.A
+		z += n.Z
+	}
+	return a, z
+}
+
+// check verifies the invariants of the simulation at iteration iter.
+func (e *Engine) check(iter int) error {
+	a, z := e.totals()
+	if a != e.totA {
+		return &InvariantError{
+			Iter: iter,
+			Msg:  fmt.Sprintf("total mass number is %d, want %d", a, e.totA),
+		}
+	}
+	if z != e.totZ {
+		return &InvariantError{
+			Iter: iter,
+			Msg:  fmt.Sprintf("total atomic number is %d, want %d", z, e.totZ),
+		}
+	}
+	for i, n := range e.nuclei {
+		if !e.Chart.Allows(n) {
+			return &InvariantError{
+				Iter: iter,
+				Msg:  fmt.Sprintf("nucleus #%d (%v) outside of chart %+v", i, n, e.chart()),
+			}
+		}
+	}
+	return nil
+}
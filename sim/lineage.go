@@ -0,0 +1,215 @@
+package sim
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Lineage records the fusion history of the nuclei of a run.
+//
+// Each nucleus ever present during the run is identified by an ID, its
+// index in Nodes.
+// Nuclei of the initial population have IDs 0 to N-1, injected nuclei
+// and fusion products are given the next available ID.
+type Lineage struct {
+	Nodes []LineageNode // all the nuclei of the run, indexed by ID
+	Final []int         // IDs of the nuclei present at the end of the run
+}
+
+// LineageNode describes a nucleus of a run and its parents.
+type LineageNode struct {
+	Nucleus Nucleus
+	Iter    int    // iteration at which the nucleus was created
+	Parents [2]int // IDs of the fused nuclei (-1 for initial and injected nuclei, and second parent of captures)
+}
+
+// Initial returns whether the nucleus with the provided ID was not
+// produced by fusion, i.e. belongs to the initial population or was
+// injected by a Source.
+func (l *Lineage) Initial(id int) bool {
+	return l.Nodes[id].Parents[0] < 0
+}
+
+// Ancestors returns the sorted IDs of all the nuclei that went into
+// the nucleus with the provided ID.
+func (l *Lineage) Ancestors(id int) []int {
+	var ids []int
+	l.walk(id, func(id int) {
+		ids = append(ids, id)
+	})
+	sort.Ints(ids)
+	return ids
+}
+
+// Roots returns the sorted IDs of the initial or injected nuclei
+// that went into the nucleus with the provided ID.
+func (l *Lineage) Roots(id int) []int {
+	var ids []int
+	if l.Initial(id) {
+		return []int{id}
+	}
+	l.walk(id, func(id int) {
+		if l.Initial(id) {
+			ids = append(ids, id)
+		}
+	})
+	sort.Ints(ids)
+	return ids
+}
+
+// Find returns the IDs of the nuclei n present at the end of the run.
+func (l *Lineage) Find(n Nucleus) []int {
+	var ids []int
+	for _, id := range l.Final {
+		if l.Nodes[id].Nucleus == n {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// walk calls fn for all the ancestors of the nucleus with the provided ID.
+func (l *Lineage) walk(id int, fn func(id int)) {
+	queue := []int{id}
+	for len(queue) > 0 {
+		cur := l.Nodes[queue[0]]
+		queue = queue[1:]
+		if cur.Parents[0] < 0 {
+			continue
+		}
+		for _, p := range cur.Parents {
+			if p < 0 {
+				continue
+			}
+			fn(p)
+			queue = append(queue, p)
+		}
+	}
+}
+
+// WriteJSON writes the lineage to w, in JSON.
+func (l *Lineage) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(l)
+}
+
+// WriteDOT writes the ancestry forest of the nuclei with the provided IDs
+// to w, as a Graphviz DOT graph.
+// WriteDOT writes the ancestry of all the final nuclei if no ID is provided.
+func (l *Lineage) WriteDOT(w io.Writer, ids ...int) error {
+	if len(ids) == 0 {
+		ids = l.Final
+	}
+
+	seen := make(map[int]bool)
+	nodes := []int{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			nodes = append(nodes, id)
+		}
+		l.walk(id, func(id int) {
+			if !seen[id] {
+				seen[id] = true
+				nodes = append(nodes, id)
+			}
+		})
+	}
+	sort.Ints(nodes)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph lineage {\n\trankdir=LR;\n")
+	for _, id := range nodes {
+		node := l.Nodes[id]
+		fmt.Fprintf(bw, "\tn%d [label=%q];\n", id, fmt.Sprintf("%s #%d", node.Nucleus.Name(), id))
+	}
+	for _, id := range nodes {
+		node := l.Nodes[id]
+		if node.Parents[0] < 0 {
+			continue
+		}
+		for _, p := range node.Parents {
+			if p < 0 {
+				continue
+			}
+			fmt.Fprintf(bw, "\tn%d -> n%d [label=\"%d\"];\n", p, id, node.Iter)
+		}
+	}
+	fmt.Fprintf(bw, "}\n")
+	return bw.Flush()
+}
+
+// Lineage returns the fusion history of the nuclei of the last run,
+// or nil if TrackLineage was not set.
+func (e *Engine) Lineage() *Lineage {
+	if !e.TrackLineage {
+		return nil
+	}
+	l := &Lineage{
+		Nodes: e.lineage,
+		Final: make([]int, len(e.ids)),
+	}
+	copy(l.Final, e.ids)
+	sort.Ints(l.Final)
+	return l
+}
+
+// initLineage assigns IDs to the nuclei of the initial population.
+func (e *Engine) initLineage() {
+	e.ids = nil
+	e.lineage = nil
+	if !e.TrackLineage {
+		return
+	}
+	e.ids = make([]int, len(e.nuclei))
+	e.lineage = make([]LineageNode, len(e.nuclei), 2*len(e.nuclei))
+	for i, n := range e.nuclei {
+		e.ids[i] = i
+		e.lineage[i] = LineageNode{Nucleus: n, Parents: [2]int{-1, -1}}
+	}
+}
+
+// addInjected records the injection of the nucleus n, appended to
+// the population.
+func (e *Engine) addInjected(n Nucleus) {
+	if !e.TrackLineage {
+		return
+	}
+	e.ids = append(e.ids, len(e.lineage))
+	e.lineage = append(e.lineage, LineageNode{
+		Nucleus: n,
+		Iter:    e.iter,
+		Parents: [2]int{-1, -1},
+	})
+}
+
+// addCaptured records the electron capture of the nucleus at index i,
+// turning it into o.
+func (e *Engine) addCaptured(i int, o Nucleus) {
+	if !e.TrackLineage {
+		return
+	}
+	id := len(e.lineage)
+	e.lineage = append(e.lineage, LineageNode{
+		Nucleus: o,
+		Iter:    e.iter,
+		Parents: [2]int{e.ids[i], -1},
+	})
+	e.ids[i] = id
+}
+
+// addLineage records the fusion of the nuclei at indices i and j into o.
+func (e *Engine) addLineage(i, j int, o Nucleus) {
+	if !e.TrackLineage {
+		return
+	}
+	id := len(e.lineage)
+	e.lineage = append(e.lineage, LineageNode{
+		Nucleus: o,
+		Iter:    e.iter,
+		Parents: [2]int{e.ids[i], e.ids[j]},
+	})
+	e.ids[i] = id
+}
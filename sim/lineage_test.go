@@ -0,0 +1,173 @@
+package sim
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestLineage(t *testing.T) {
+	nNa := Nucleus{A: 24, Z: 11}
+	// 0, 1 and 2 are initial, 3 = 0+1, 4 is 3 after a capture,
+	// 5 is injected, 6 = 4+2 and 5 is ejected.
+	l := &Lineage{
+		Nodes: []LineageNode{
+			{Nucleus: nC, Parents: [2]int{-1, -1}},
+			{Nucleus: nC, Parents: [2]int{-1, -1}},
+			{Nucleus: nO, Parents: [2]int{-1, -1}},
+			{Nucleus: nMg, Iter: 1, Parents: [2]int{0, 1}},
+			{Nucleus: nNa, Iter: 1, Parents: [2]int{3, -1}},
+			{Nucleus: nC, Iter: 2, Parents: [2]int{-1, -1}},
+			{Nucleus: Nucleus{A: 40, Z: 19}, Iter: 3, Parents: [2]int{4, 2}},
+		},
+		Final: []int{6},
+	}
+
+	for _, tc := range []struct {
+		id        int
+		initial   bool
+		ancestors []int
+		roots     []int
+	}{
+		{id: 0, initial: true, roots: []int{0}},
+		{id: 3, ancestors: []int{0, 1}, roots: []int{0, 1}},
+		{id: 4, ancestors: []int{0, 1, 3}, roots: []int{0, 1}},
+		{id: 5, initial: true, roots: []int{5}},
+		{id: 6, ancestors: []int{0, 1, 2, 3, 4}, roots: []int{0, 1, 2}},
+	} {
+		if got := l.Initial(tc.id); got != tc.initial {
+			t.Errorf("#%d: invalid initial: got=%v, want=%v", tc.id, got, tc.initial)
+		}
+		if got := l.Ancestors(tc.id); !reflect.DeepEqual(got, tc.ancestors) {
+			t.Errorf("#%d: invalid ancestors: got=%v, want=%v", tc.id, got, tc.ancestors)
+		}
+		if got := l.Roots(tc.id); !reflect.DeepEqual(got, tc.roots) {
+			t.Errorf("#%d: invalid roots: got=%v, want=%v", tc.id, got, tc.roots)
+		}
+	}
+
+	if got, want := l.Find(Nucleus{A: 40, Z: 19}), []int{6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid IDs: got=%v, want=%v", got, want)
+	}
+	if got := l.Find(nC); got != nil {
+		t.Errorf("invalid IDs: got=%v, want=nil", got)
+	}
+}
+
+func TestLineageRun(t *testing.T) {
+	e := Engine{
+		NumIters:     2000,
+		NumCarbons:   60,
+		Seed:         1234,
+		Conditions:   Conditions{T9: 3, Rho: 1e9},
+		Capture:      Capture{Rate: 1},
+		Sources:      []Source{{Every: 100, N: 20}},
+		Sinks:        []Sink{{Every: 250, Fraction: 0.05}},
+		TrackLineage: true,
+	}
+	err := e.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	npop := len(e.nuclei)
+	for e.Next() {
+	}
+	if err := e.Err(); err != nil {
+		t.Fatal(err)
+	}
+	l := e.Lineage()
+
+	var fusions, injections, captures int
+	used := make(map[int]bool)
+	for id, node := range l.Nodes {
+		switch {
+		case node.Parents[0] < 0:
+			if id >= npop {
+				injections++
+			}
+		case node.Parents[1] < 0:
+			captures++
+		default:
+			fusions++
+		}
+		for _, p := range node.Parents {
+			if p >= 0 {
+				used[p] = true
+			}
+		}
+	}
+	final := make(map[int]bool, len(l.Final))
+	for _, id := range l.Final {
+		final[id] = true
+	}
+	ejections := 0
+	for id := range l.Nodes {
+		if !used[id] && !final[id] {
+			ejections++
+		}
+	}
+	if fusions == 0 || injections == 0 || captures == 0 || ejections == 0 {
+		t.Fatalf(
+			"run not covering all the lineage events: fusions=%d, injections=%d, captures=%d, ejections=%d",
+			fusions, injections, captures, ejections,
+		)
+	}
+
+	if !sort.IntsAreSorted(l.Final) {
+		t.Errorf("final IDs not sorted")
+	}
+	counts := make(map[Nucleus]int)
+	for _, id := range l.Final {
+		counts[l.Nodes[id].Nucleus]++
+	}
+	if got, want := counts, e.Counts(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("final nuclei differ from the population:\ngot= %v\nwant=%v", got, want)
+	}
+	for n, c := range counts {
+		ids := l.Find(n)
+		if len(ids) != c {
+			t.Errorf("%v: invalid number of IDs: got=%d, want=%d", n, len(ids), c)
+		}
+		for _, id := range ids {
+			if l.Nodes[id].Nucleus != n {
+				t.Errorf("%v: invalid nucleus of #%d: %v", n, id, l.Nodes[id].Nucleus)
+			}
+		}
+	}
+
+	for _, id := range l.Final {
+		node := l.Nodes[id]
+		roots := l.Roots(id)
+		a := 0
+		for _, r := range roots {
+			if !l.Initial(r) {
+				t.Errorf("#%d: root #%d is not initial", id, r)
+			}
+			a += l.Nodes[r].Nucleus.A
+		}
+		if a != node.Nucleus.A {
+			t.Errorf("#%d: invalid mass number of the roots of %v: %d", id, node.Nucleus, a)
+		}
+
+		ancestors := l.Ancestors(id)
+		if !sort.IntsAreSorted(ancestors) {
+			t.Errorf("#%d: ancestors not sorted", id)
+		}
+		for _, p := range ancestors {
+			if p >= id || l.Nodes[p].Iter > node.Iter {
+				t.Errorf("#%d: ancestor #%d created after the nucleus", id, p)
+			}
+		}
+		if l.Initial(id) {
+			if len(ancestors) != 0 || !reflect.DeepEqual(roots, []int{id}) {
+				t.Errorf("#%d: invalid ancestry of an initial nucleus: ancestors=%v, roots=%v", id, ancestors, roots)
+			}
+			continue
+		}
+		for _, p := range node.Parents {
+			if k := sort.SearchInts(ancestors, p); p >= 0 && (k == len(ancestors) || ancestors[k] != p) {
+				t.Errorf("#%d: parent #%d not in the ancestors", id, p)
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package sim
+
+import (
+	"math"
+)
+
+// bindings holds the experimental total binding energies, in MeV,
+// of a selection of nuclei (AME2016).
+var bindings = map[Nucleus]float64{
+	{A: 1, Z: 0}:   0,
+	{A: 1, Z: 1}:   0,
+	{A: 2, Z: 1}:   2.224566,
+	{A: 3, Z: 1}:   8.481798,
+	{A: 3, Z: 2}:   7.718043,
+	{A: 4, Z: 2}:   28.295673,
+	{A: 12, Z: 6}:  92.161726,
+	{A: 13, Z: 6}:  97.108020,
+	{A: 14, Z: 7}:  104.658596,
+	{A: 16, Z: 8}:  127.619296,
+	{A: 20, Z: 10}: 160.644859,
+	{A: 22, Z: 10}: 177.770327,
+	{A: 23, Z: 11}: 186.564376,
+	{A: 24, Z: 12}: 198.256962,
+	{A: 27, Z: 13}: 224.951935,
+	{A: 28, Z: 14}: 236.536898,
+	{A: 32, Z: 16}: 271.780775,
+	{A: 36, Z: 18}: 306.716359,
+	{A: 40, Z: 20}: 342.052104,
+	{A: 44, Z: 22}: 375.474780,
+	{A: 48, Z: 24}: 411.462085,
+	{A: 52, Z: 24}: 456.345323,
+	{A: 52, Z: 26}: 447.697612,
+	{A: 54, Z: 24}: 474.003486,
+	{A: 54, Z: 25}: 471.844705,
+	{A: 54, Z: 26}: 471.759692,
+	{A: 55, Z: 25}: 482.070748,
+	{A: 55, Z: 26}: 481.057255,
+	{A: 55, Z: 27}: 476.825808,
+	{A: 56, Z: 25}: 489.343634,
+	{A: 56, Z: 26}: 492.253958,
+	{A: 56, Z: 27}: 486.905550,
+	{A: 56, Z: 28}: 483.988009,
+	{A: 57, Z: 28}: 494.239360,
+	{A: 58, Z: 26}: 509.949520,
+	{A: 58, Z: 27}: 506.855238,
+	{A: 58, Z: 28}: 506.454296,
+	{A: 60, Z: 30}: 514.991536,
+}
+
+// BindingEnergy returns the total binding energy of the nucleus n, in MeV.
+// Experimental values are used when available, the semi-empirical
+// mass formula of Bethe and Weizsäcker otherwise.
+func BindingEnergy(n Nucleus) float64 {
+	if b, ok := bindings[n]; ok {
+		return b
+	}
+	return semf(n)
+}
+
+// QValue returns the energy released by the fusion of the nuclei
+// n1 and n2 into o, in MeV.
+func QValue(n1, n2, o Nucleus) float64 {
+	return BindingEnergy(o) - BindingEnergy(n1) - BindingEnergy(n2)
+}
+
+// semf returns the binding energy of the nucleus n, in MeV, computed
+// with the semi-empirical mass formula.
+func semf(n Nucleus) float64 {
+	if n.A <= 1 {
+		return 0
+	}
+	const (
+		aV = 15.75 // volume term
+		aS = 17.8  // surface term
+		aC = 0.711 // Coulomb term
+		aA = 23.7  // asymmetry term
+		aP = 11.18 // pairing term
+	)
+	a := float64(n.A)
+	z := float64(n.Z)
+	b := aV*a - aS*math.Pow(a, 2.0/3.0) - aC*z*(z-1)/math.Cbrt(a) - aA*(a-2*z)*(a-2*z)/a
+	switch {
+	case n.A%2 != 0:
+		// odd-A nuclei: no pairing term
+	case n.Z%2 == 0:
+		b += aP / math.Sqrt(a)
+	default:
+		b -= aP / math.Sqrt(a)
+	}
+	return math.Max(0, b)
+}
@@ -0,0 +1,313 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// FormatVersion is the version of the output format written by Engine.Run.
+//
+// Format versions:
+//   - 1: the metadata header is the JSON encoded Engine.
+//   - 2: the metadata header is the JSON encoded Metadata, followed by
+//     a comment line with the names of the columns.
+//     The configuration of the simulation is the JSON encoded RunConfig.
+const FormatVersion = 2
+
+// Version is the version of the snfusion software.
+// It may be set at link time:
+//
+//	go build -ldflags "-X github.com/astrogo/snfusion/sim.Version=v1.0.0"
+var Version = "devel"
+
+// Column describes a column of the output records.
+type Column struct {
+	Name string // name of the column (e.g. "56Ni", "attempts", "T9")
+	Unit string // unit of the column values ("u": total mass in atomic mass units, "count", "GK", "" if dimensionless)
+}
+
+// Metadata describes the content of an output file.
+type Metadata struct {
+	Format  int       // version of the output format (see FormatVersion)
+	Version string    // version of the software that created the file
+	Created time.Time // creation time of the file
+	Columns []Column  // columns of the records
+	Config  RunConfig `json:"Engine"` // configuration of the simulation
+}
+
+// RunConfig is the configuration of a simulation, as stored in the
+// metadata of output files.
+// Its JSON encoding, and the ones of the configurations it holds, are part
+// of the output format: they are mapped explicitly from and to the fields
+// of Engine (see Engine.RunConfig and RunConfig.Engine), so that changes
+// of Engine do not silently change the format of the files.
+// The stored configurations are converted from and to the types of Engine
+// they mirror, which fails to compile once these types change.
+type RunConfig struct {
+	NumIters      int              `json:"NumIters"`
+	NumCarbons    float64          `json:"NumCarbons"`
+	Seed          int64            `json:"Seed"`
+	Population    []Nucleus        `json:"Population"`
+	Model         string           `json:"Model"`
+	ModelFile     string           `json:"ModelFile,omitempty"`
+	Chart         *ChartConfig     `json:"Chart"`
+	Fallback      FallbackConfig   `json:"Fallback"`
+	Conditions    ConditionsConfig `json:"Conditions"`
+	CheckEvery    int              `json:"CheckEvery"`
+	RejectionFree bool             `json:"RejectionFree"`
+	Acceptance    bool             `json:"Acceptance"`
+	TrackLineage  bool             `json:"TrackLineage"`
+	Sources       []SourceConfig   `json:"Sources"`
+	Sinks         []SinkConfig     `json:"Sinks"`
+	Heating       HeatingConfig    `json:"Heating"`
+	Capture       CaptureConfig    `json:"Capture"`
+}
+
+// ChartConfig is the stored configuration of a Chart.
+type ChartConfig struct {
+	MaxA  int     `json:"MaxA"`
+	MaxZ  int     `json:"MaxZ"`
+	MinNZ float64 `json:"MinNZ"`
+	MaxNZ float64 `json:"MaxNZ"`
+}
+
+// FallbackConfig is the stored configuration of a Fallback.
+type FallbackConfig struct {
+	Mode  FallbackMode `json:"Mode"`
+	Norm  float64      `json:"Norm"`
+	Slope float64      `json:"Slope"`
+}
+
+// ConditionsConfig is the stored configuration of Conditions.
+type ConditionsConfig struct {
+	T9  float64 `json:"T9"`
+	Rho float64 `json:"Rho"`
+}
+
+// AbundanceConfig is the stored configuration of an Abundance.
+type AbundanceConfig struct {
+	Nucleus Nucleus `json:"Nucleus"`
+	Weight  float64 `json:"Weight"`
+}
+
+// SourceConfig is the stored configuration of a Source.
+type SourceConfig struct {
+	Every       int               `json:"Every"`
+	N           int               `json:"N"`
+	Composition []AbundanceConfig `json:"Composition"`
+}
+
+// SinkConfig is the stored configuration of a Sink.
+type SinkConfig struct {
+	Every    int               `json:"Every"`
+	Fraction float64           `json:"Fraction"`
+	Bias     []AbundanceConfig `json:"Bias"`
+}
+
+// HeatingConfig is the stored configuration of Heating.
+type HeatingConfig struct {
+	HeatCapacity float64 `json:"HeatCapacity"`
+	Cooling      float64 `json:"Cooling"`
+	RefT9        float64 `json:"RefT9"`
+}
+
+// Enabled returns whether self-heating is enabled.
+func (c HeatingConfig) Enabled() bool {
+	return Heating(c).Enabled()
+}
+
+// CaptureConfig is the stored configuration of Capture.
+type CaptureConfig struct {
+	Rate float64 `json:"Rate"`
+}
+
+// Enabled returns whether electron captures are enabled.
+func (c CaptureConfig) Enabled() bool {
+	return Capture(c).Enabled()
+}
+
+// RunConfig returns the configuration of the engine.
+func (e *Engine) RunConfig() RunConfig {
+	cfg := RunConfig{
+		NumIters:      e.NumIters,
+		NumCarbons:    e.NumCarbons,
+		Seed:          e.Seed,
+		Population:    e.Population,
+		Model:         e.Model,
+		ModelFile:     e.ModelFile,
+		Fallback:      FallbackConfig(e.Fallback),
+		Conditions:    ConditionsConfig(e.Conditions),
+		CheckEvery:    e.CheckEvery,
+		RejectionFree: e.RejectionFree,
+		Acceptance:    e.Acceptance,
+		TrackLineage:  e.TrackLineage,
+		Heating:       HeatingConfig(e.Heating),
+		Capture:       CaptureConfig(e.Capture),
+	}
+	if e.Chart != nil {
+		chart := ChartConfig(*e.Chart)
+		cfg.Chart = &chart
+	}
+	if e.Sources != nil {
+		cfg.Sources = make([]SourceConfig, len(e.Sources))
+		for i, src := range e.Sources {
+			cfg.Sources[i] = SourceConfig{
+				Every:       src.Every,
+				N:           src.N,
+				Composition: abundanceConfigs(src.Composition),
+			}
+		}
+	}
+	if e.Sinks != nil {
+		cfg.Sinks = make([]SinkConfig, len(e.Sinks))
+		for i, sink := range e.Sinks {
+			cfg.Sinks[i] = SinkConfig{
+				Every:    sink.Every,
+				Fraction: sink.Fraction,
+				Bias:     abundanceConfigs(sink.Bias),
+			}
+		}
+	}
+	return cfg
+}
+
+// Engine returns a simulation engine with the configuration c.
+func (c RunConfig) Engine() Engine {
+	e := Engine{
+		NumIters:      c.NumIters,
+		NumCarbons:    c.NumCarbons,
+		Seed:          c.Seed,
+		Population:    c.Population,
+		Model:         c.Model,
+		ModelFile:     c.ModelFile,
+		Fallback:      Fallback(c.Fallback),
+		Conditions:    Conditions(c.Conditions),
+		CheckEvery:    c.CheckEvery,
+		RejectionFree: c.RejectionFree,
+		Acceptance:    c.Acceptance,
+		TrackLineage:  c.TrackLineage,
+		Heating:       Heating(c.Heating),
+		Capture:       Capture(c.Capture),
+	}
+	if c.Chart != nil {
+		chart := Chart(*c.Chart)
+		e.Chart = &chart
+	}
+	if c.Sources != nil {
+		e.Sources = make([]Source, len(c.Sources))
+		for i, src := range c.Sources {
+			e.Sources[i] = Source{
+				Every:       src.Every,
+				N:           src.N,
+				Composition: abundances(src.Composition),
+			}
+		}
+	}
+	if c.Sinks != nil {
+		e.Sinks = make([]Sink, len(c.Sinks))
+		for i, sink := range c.Sinks {
+			e.Sinks[i] = Sink{
+				Every:    sink.Every,
+				Fraction: sink.Fraction,
+				Bias:     abundances(sink.Bias),
+			}
+		}
+	}
+	return e
+}
+
+func abundanceConfigs(abs []Abundance) []AbundanceConfig {
+	if abs == nil {
+		return nil
+	}
+	o := make([]AbundanceConfig, len(abs))
+	for i, ab := range abs {
+		o[i] = AbundanceConfig(ab)
+	}
+	return o
+}
+
+func abundances(abs []AbundanceConfig) []Abundance {
+	if abs == nil {
+		return nil
+	}
+	o := make([]Abundance, len(abs))
+	for i, ab := range abs {
+		o[i] = Abundance(ab)
+	}
+	return o
+}
+
+// Columns returns the columns of the output records of the engine.
+func (e *Engine) Columns() []Column {
+	pop := e.Population
+	if pop == nil {
+		pop = Population
+	}
+	cols := make([]Column, 0, len(pop)+len(AcceptanceColumns)+2)
+	for _, n := range pop {
+		cols = append(cols, Column{Name: n.Name(), Unit: "u"})
+	}
+	if e.Acceptance {
+		for _, name := range AcceptanceColumns {
+			cols = append(cols, Column{Name: name, Unit: "count"})
+		}
+	}
+	if e.Heating.Enabled() {
+		cols = append(cols, Column{Name: "T9", Unit: "GK"})
+	}
+	if e.Capture.Enabled() {
+		cols = append(cols, Column{Name: "Ye"})
+	}
+	return cols
+}
+
+// Metadata returns the metadata of the output of the engine.
+func (e *Engine) Metadata() Metadata {
+	return Metadata{
+		Format:  FormatVersion,
+		Version: Version,
+		Created: time.Now().UTC().Truncate(time.Second),
+		Columns: e.Columns(),
+		Config:  e.RunConfig(),
+	}
+}
+
+// ParseMetadata decodes the JSON encoded metadata header of an output file,
+// of any format version.
+// Headers of older versions are upgraded to the current Metadata layout:
+// fields missing from these versions are derived from the Engine when
+// possible and left zero otherwise.
+func ParseMetadata(data []byte) (Metadata, error) {
+	var probe struct {
+		Format *int
+	}
+	err := json.Unmarshal(data, &probe)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	switch {
+	case probe.Format == nil:
+		// format version 1: the header only holds the engine.
+		var meta Metadata
+		err = json.Unmarshal(data, &meta.Config)
+		if err != nil {
+			return meta, err
+		}
+		e := meta.Config.Engine()
+		meta.Format = 1
+		meta.Columns = e.Columns()
+		return meta, nil
+	case *probe.Format <= 0 || *probe.Format > FormatVersion:
+		return Metadata{}, fmt.Errorf(
+			"sim: unsupported output format version %d (max: %d)",
+			*probe.Format, FormatVersion,
+		)
+	}
+
+	var meta Metadata
+	err = json.Unmarshal(data, &meta)
+	return meta, err
+}
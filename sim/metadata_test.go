@@ -0,0 +1,130 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMetadataGolden(t *testing.T) {
+	want, err := ioutil.ReadFile("testdata/header-v2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = bytes.TrimSpace(want)
+
+	chart := DefaultChart
+	e := Engine{
+		NumIters:   30000,
+		NumCarbons: 60,
+		Seed:       1234,
+		Population: Population,
+		Model:      DefaultModel,
+		Chart:      &chart,
+		Fallback:   Fallback{Norm: 1, Slope: 1},
+	}
+	meta := e.Metadata()
+	meta.Created = time.Date(2017, 1, 14, 21, 32, 4, 0, time.UTC)
+	if meta.Format != 2 {
+		t.Fatalf("invalid format version: got=%d, want=2", meta.Format)
+	}
+
+	got, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("invalid metadata header:\ngot= %s\nwant=%s", got, want)
+	}
+
+	parsed, err := ParseMetadata(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, meta) {
+		t.Fatalf("invalid parsed metadata:\ngot= %+v\nwant=%+v", parsed, meta)
+	}
+	pe := parsed.Config.Engine()
+	if cfg := pe.RunConfig(); !reflect.DeepEqual(cfg, meta.Config) {
+		t.Fatalf("invalid round trip of the configuration:\ngot= %+v\nwant=%+v", cfg, meta.Config)
+	}
+}
+
+func TestMetadataV1(t *testing.T) {
+	const hdr = `{"NumIters":100,"NumCarbons":50,"Seed":42,"Population":[{"A":12,"Z":6},{"A":16,"Z":8}],"Acceptance":true}`
+	meta, err := ParseMetadata([]byte(hdr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Format != 1 {
+		t.Fatalf("invalid format version: got=%d, want=1", meta.Format)
+	}
+	if meta.Config.NumIters != 100 || meta.Config.Seed != 42 || !meta.Config.Acceptance {
+		t.Fatalf("invalid configuration: %+v", meta.Config)
+	}
+	if got, want := len(meta.Columns), 2+len(AcceptanceColumns); got != want {
+		t.Fatalf("invalid number of columns: got=%d, want=%d", got, want)
+	}
+}
+
+func TestMetadataUnsupported(t *testing.T) {
+	for _, hdr := range []string{
+		`{"Format":3}`,
+		`{"Format":0}`,
+		`{"Format":-1}`,
+	} {
+		_, err := ParseMetadata([]byte(hdr))
+		if err == nil {
+			t.Errorf("%s: expected an error for an unsupported format version", hdr)
+		}
+	}
+}
+
+func TestRunConfigRoundTrip(t *testing.T) {
+	chart := Chart{MaxA: 60, MaxZ: 30, MinNZ: 0.9, MaxNZ: 1.3}
+	e := Engine{
+		NumIters:   1000,
+		NumCarbons: 40,
+		Seed:       42,
+		Population: []Nucleus{nC, nO, nNi},
+		Model:      "coulomb",
+		Chart:      &chart,
+		Fallback:   Fallback{Mode: FallbackCoulomb, Norm: 0.5, Slope: 2},
+		Conditions: Conditions{T9: 3, Rho: 1e9},
+		Sources: []Source{
+			{Every: 10, N: 5, Composition: []Abundance{{Nucleus: nC, Weight: 1}}},
+			{Every: 20, N: 1},
+		},
+		Sinks:   []Sink{{Every: 50, Fraction: 0.1, Bias: []Abundance{{Nucleus: nNi, Weight: 2}}}},
+		Heating: Heating{HeatCapacity: 100, Cooling: 0.01, RefT9: 2},
+		Capture: Capture{Rate: 1},
+	}
+	js, err := json.Marshal(e.Metadata())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"Chart":{"MaxA":60,"MaxZ":30,"MinNZ":0.9,"MaxNZ":1.3}`,
+		`"Fallback":{"Mode":"coulomb","Norm":0.5,"Slope":2}`,
+		`"Conditions":{"T9":3,"Rho":1000000000}`,
+		`"Sources":[{"Every":10,"N":5,"Composition":[{"Nucleus":{"A":12,"Z":6},"Weight":1}]},{"Every":20,"N":1,"Composition":null}]`,
+		`"Sinks":[{"Every":50,"Fraction":0.1,"Bias":[{"Nucleus":{"A":56,"Z":28},"Weight":2}]}]`,
+		`"Heating":{"HeatCapacity":100,"Cooling":0.01,"RefT9":2}`,
+		`"Capture":{"Rate":1}`,
+	} {
+		if !bytes.Contains(js, []byte(want)) {
+			t.Errorf("missing %s in metadata header:\n%s", want, js)
+		}
+	}
+
+	meta, err := ParseMetadata(js)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := meta.Config.Engine(); !reflect.DeepEqual(got, e) {
+		t.Fatalf("invalid round trip of the configuration:\ngot= %+v\nwant=%+v", got, e)
+	}
+}
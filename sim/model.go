@@ -0,0 +1,187 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultModel is the name of the fusion model used by an Engine
+// when none is specified.
+const DefaultModel = "standard"
+
+// Conditions describes the thermodynamic conditions under which
+// a pair of nuclei may fuse.
+// A zero value lets the fusion model use its own defaults.
+type Conditions struct {
+	T9  float64 // temperature in GK
+	Rho float64 // density in g/cm^3
+}
+
+// FusionModel describes the reaction rules of a simulation.
+type FusionModel interface {
+	// Products returns the product of the fusion of the nuclei ni and nj,
+	// or false if these nuclei can not fuse.
+	Products(ni, nj Nucleus) (Nucleus, bool)
+
+	// Probability returns the probability for the nuclei ni and nj
+	// to fuse under the conditions c.
+	Probability(ni, nj Nucleus, c Conditions) (float64, error)
+}
+
+// Tabulator is implemented by fusion models relying on a table of
+// cross-sections.
+// Engines use it to report pairs of nuclei missing from that table.
+type Tabulator interface {
+	// Tabulated returns whether the pair of nuclei ni and nj
+	// has a tabulated cross-section.
+	Tabulated(ni, nj Nucleus) bool
+}
+
+// ModelFunc creates a fusion model configured after the Engine e.
+type ModelFunc func(e *Engine) (FusionModel, error)
+
+var models = struct {
+	sync.RWMutex
+	db map[string]ModelFunc
+}{
+	db: make(map[string]ModelFunc),
+}
+
+// Register makes the fusion model created by fn available under the
+// provided name.
+// Register panics if it is called twice with the same name or if fn is nil.
+func Register(name string, fn ModelFunc) {
+	models.Lock()
+	defer models.Unlock()
+	if fn == nil {
+		panic("sim: Register model is nil")
+	}
+	if _, dup := models.db[name]; dup {
+		panic("sim: Register called twice for model " + name)
+	}
+	models.db[name] = fn
+}
+
+// Models returns the sorted list of names of the registered fusion models.
+func Models() []string {
+	models.RLock()
+	defer models.RUnlock()
+	names := make([]string, 0, len(models.db))
+	for name := range models.db {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewModel creates the fusion model registered under the provided name,
+// configured after the Engine e.
+func NewModel(name string, e *Engine) (FusionModel, error) {
+	models.RLock()
+	fn, ok := models.db[name]
+	models.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("sim: unknown fusion model %q", name)
+	}
+	return fn(e)
+}
+
+// Standard is the default fusion model.
+// Products are the sum of the fusing nuclei, restricted to Chart.
+// Probabilities are given by tabulated cross-sections, completed by
+// the Fallback model for the pairs missing from that table.
+// When RefT9 is set, probabilities are scaled from RefT9 to the temperature
+// of the conditions by the ratio of the Gamow factors of the fusing nuclei.
+type Standard struct {
+	Chart    Chart
+	Fallback Fallback
+	RefT9    float64          // temperature of the tabulated cross-sections, in GK (zero: no temperature dependence)
+	Table    map[Pair]float64 // tabulated cross-sections (zero: CrossSections())
+}
+
+// Products implements FusionModel.
+func (m Standard) Products(ni, nj Nucleus) (Nucleus, bool) {
+	return m.Chart.Fuse(ni, nj)
+}
+
+// Probability implements FusionModel.
+func (m Standard) Probability(ni, nj Nucleus, c Conditions) (float64, error) {
+	xs, ok := m.xsect(ni, nj)
+	if !ok {
+		var err error
+		xs, err = m.Fallback.probability(ni, nj)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return scaleT9(xs, ni, nj, m.RefT9, c.T9), nil
+}
+
+// Tabulated implements Tabulator.
+func (m Standard) Tabulated(ni, nj Nucleus) bool {
+	_, ok := m.xsect(ni, nj)
+	return ok
+}
+
+// xsect returns the tabulated cross-section of the nuclei ni and nj.
+func (m Standard) xsect(ni, nj Nucleus) (float64, bool) {
+	if m.Table == nil {
+		xs, ok := xsects[Pair{ni, nj}]
+		return xs, ok
+	}
+	xs, ok := m.Table[Pair{ni, nj}]
+	if !ok {
+		xs, ok = m.Table[Pair{nj, ni}]
+	}
+	return xs, ok
+}
+
+// CrossSections returns a copy of the cross-sections tabulated for the
+// Standard fusion model, with a single entry per pair of nuclei,
+// lightest nucleus first.
+func CrossSections() map[Pair]float64 {
+	table := make(map[Pair]float64, len(xsects)/2+1)
+	for p, xs := range xsects {
+		table[p.sorted()] = xs
+	}
+	return table
+}
+
+// Coulomb is a fusion model ignoring tabulated cross-sections.
+// Products are the sum of the fusing nuclei, restricted to Chart.
+// Probabilities are estimated from the Coulomb barrier of the
+// fusing nuclei (see Fallback.Coulomb.)
+// When RefT9 is set, probabilities are scaled from RefT9 to the temperature
+// of the conditions by the ratio of the Gamow factors of the fusing nuclei.
+type Coulomb struct {
+	Chart Chart
+	Norm  float64 // probability of the reference channel (zero: 1)
+	Slope float64 // steepness of the barrier suppression (zero: 1)
+	RefT9 float64 // temperature of the barrier estimate, in GK (zero: no temperature dependence)
+}
+
+// Products implements FusionModel.
+func (m Coulomb) Products(ni, nj Nucleus) (Nucleus, bool) {
+	return m.Chart.Fuse(ni, nj)
+}
+
+// Probability implements FusionModel.
+func (m Coulomb) Probability(ni, nj Nucleus, c Conditions) (float64, error) {
+	fb := Fallback{Norm: m.Norm, Slope: m.Slope}
+	return scaleT9(fb.Coulomb(ni, nj), ni, nj, m.RefT9, c.T9), nil
+}
+
+func init() {
+	Register(DefaultModel, func(e *Engine) (FusionModel, error) {
+		return Standard{Chart: e.chart(), Fallback: e.Fallback, RefT9: e.refT9()}, nil
+	})
+	Register("coulomb", func(e *Engine) (FusionModel, error) {
+		return Coulomb{
+			Chart: e.chart(),
+			Norm:  e.Fallback.Norm,
+			Slope: e.Fallback.Slope,
+			RefT9: e.refT9(),
+		}, nil
+	})
+}
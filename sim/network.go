@@ -0,0 +1,192 @@
+package sim
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Reaction is a fusion channel of a reaction network.
+type Reaction struct {
+	Pair    Pair
+	Product Nucleus
+	Weight  float64 // fusion probability or number of fusions of the pair
+}
+
+// Network is the network of fusion reactions between a set of nuclei.
+type Network struct {
+	Nuclei    Nuclei     // nuclei of the network, sorted by mass number
+	Reactions []Reaction // reactions of the network, sorted by pair
+}
+
+// maxNetworkNuclei is the maximum number of nuclei of a reaction network.
+const maxNetworkNuclei = 1000
+
+// NewNetwork returns the network of reactions the fusion model m allows
+// between the provided nuclei and all the nuclei they may produce,
+// weighted by their probability under the conditions c.
+// Reactions with a zero probability are kept in the network, as are the
+// reactions of pairs without tabulated cross-section whose probability
+// can not be computed (see FallbackStrict), with a zero weight.
+// NewNetwork returns an error if the network does not close within
+// a bounded number of nuclei, e.g. when the chart of nuclides of m has
+// no limit.
+func NewNetwork(m FusionModel, nuclei []Nucleus, c Conditions) (*Network, error) {
+	seen := make(map[Nucleus]bool, len(nuclei))
+	var net Network
+	for _, n := range nuclei {
+		if !seen[n] {
+			seen[n] = true
+			net.Nuclei = append(net.Nuclei, n)
+		}
+	}
+
+	// close the network under fusion: each new nucleus is paired with
+	// all the nuclei found so far, itself included.
+	for k := 0; k < len(net.Nuclei); k++ {
+		if k >= maxNetworkNuclei {
+			return nil, fmt.Errorf(
+				"sim: reaction network does not close within %d nuclei",
+				maxNetworkNuclei,
+			)
+		}
+		nk := net.Nuclei[k]
+		for _, ni := range net.Nuclei[:k+1] {
+			o, ok := m.Products(ni, nk)
+			if !ok {
+				continue
+			}
+			p, err := m.Probability(ni, nk, c)
+			if err != nil {
+				tab, ok := m.(Tabulator)
+				if !ok || tab.Tabulated(ni, nk) {
+					return nil, err
+				}
+				p = 0
+			}
+			net.Reactions = append(net.Reactions, Reaction{
+				Pair:    Pair{ni, nk}.sorted(),
+				Product: o,
+				Weight:  p,
+			})
+			if !seen[o] {
+				seen[o] = true
+				net.Nuclei = append(net.Nuclei, o)
+			}
+		}
+	}
+
+	net.sort()
+	return &net, nil
+}
+
+// InitialNuclei returns the species of the initial population of the last
+// run, and the species its sources may inject, sorted by mass number.
+func (e *Engine) InitialNuclei() Nuclei {
+	seen := make(map[Nucleus]bool)
+	var nuclei Nuclei
+	add := func(n Nucleus) {
+		if !seen[n] {
+			seen[n] = true
+			nuclei = append(nuclei, n)
+		}
+	}
+	for _, n := range e.initial {
+		add(n)
+	}
+	for _, src := range e.Sources {
+		if src.Every <= 0 || src.N <= 0 {
+			continue
+		}
+		comp := src.Composition
+		if len(comp) == 0 {
+			comp = []Abundance{
+				{Nucleus: nC, Weight: e.NumCarbons},
+				{Nucleus: nO, Weight: 100 - e.NumCarbons},
+			}
+		}
+		for _, ab := range comp {
+			if ab.Weight > 0 {
+				add(ab.Nucleus)
+			}
+		}
+	}
+	sort.Sort(nuclei)
+	return nuclei
+}
+
+// NewFluxNetwork returns the network of reactions attempted during a run,
+// weighted by their number of fusions.
+// Products are given by the fusion model m.
+func NewFluxNetwork(m FusionModel, rf ReactionFlux) *Network {
+	seen := make(map[Nucleus]bool)
+	var net Network
+	add := func(n Nucleus) {
+		if !seen[n] {
+			seen[n] = true
+			net.Nuclei = append(net.Nuclei, n)
+		}
+	}
+	for _, f := range rf {
+		o, ok := m.Products(f.Pair[0], f.Pair[1])
+		if !ok {
+			continue
+		}
+		add(f.Pair[0])
+		add(f.Pair[1])
+		add(o)
+		net.Reactions = append(net.Reactions, Reaction{
+			Pair:    f.Pair,
+			Product: o,
+			Weight:  float64(f.Fusions),
+		})
+	}
+	net.sort()
+	return &net
+}
+
+func (net *Network) sort() {
+	sort.Sort(net.Nuclei)
+	sort.SliceStable(net.Reactions, func(i, j int) bool {
+		return pairLess(net.Reactions[i].Pair, net.Reactions[j].Pair)
+	})
+}
+
+// WriteDOT writes the network to w, as a Graphviz DOT graph.
+// Nuclei are pinned at their (N, Z) position on the chart of nuclides, to be
+// laid out with neato -n or fdp.
+// Each reaction is drawn as edges from both reactants to the product, with a
+// width given by the weight of the reaction.
+// Reactions with a zero weight are drawn as dashed edges.
+func (net *Network) WriteDOT(w io.Writer) error {
+	var max float64
+	for _, r := range net.Reactions {
+		if r.Weight > max {
+			max = r.Weight
+		}
+	}
+
+	const scale = 72 // points per unit of N or Z
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph network {\n\tnode [shape=box];\n")
+	for _, n := range net.Nuclei {
+		fmt.Fprintf(bw, "\t%q [pos=\"%d,%d!\"];\n", n.Name(), scale*n.N(), scale*n.Z)
+	}
+	for _, r := range net.Reactions {
+		attrs := "style=dashed, color=gray"
+		if r.Weight > 0 {
+			attrs = fmt.Sprintf("penwidth=%.3g", 1+4*r.Weight/max)
+		}
+		ni, nj := r.Pair[0], r.Pair[1]
+		label := fmt.Sprintf("%s (%.3g)", nj.Name(), r.Weight)
+		fmt.Fprintf(bw, "\t%q -> %q [label=%q, %s];\n", ni.Name(), r.Product.Name(), label, attrs)
+		if ni != nj {
+			label = fmt.Sprintf("%s (%.3g)", ni.Name(), r.Weight)
+			fmt.Fprintf(bw, "\t%q -> %q [label=%q, %s];\n", nj.Name(), r.Product.Name(), label, attrs)
+		}
+	}
+	fmt.Fprintf(bw, "}\n")
+	return bw.Flush()
+}
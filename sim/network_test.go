@@ -0,0 +1,76 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNetworkStrict(t *testing.T) {
+	m := Standard{
+		Chart:    DefaultChart,
+		Fallback: Fallback{Mode: FallbackStrict},
+		Table:    map[Pair]float64{{nC, nC}: 0.5},
+	}
+	net, err := NewNetwork(m, []Nucleus{nC, nO}, Conditions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range net.Reactions {
+		want := 0.0
+		if r.Pair == (Pair{nC, nC}) {
+			want = 0.5
+		}
+		if r.Weight != want {
+			t.Errorf("%v: invalid weight: got=%v, want=%v", r.Pair, r.Weight, want)
+		}
+	}
+	if len(net.Reactions) < 3 {
+		t.Errorf("untabulated reactions missing from the network: %v", net.Reactions)
+	}
+}
+
+func TestInitialNuclei(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		e    Engine
+		want Nuclei
+	}{
+		{
+			name: "carbon-oxygen",
+			e:    Engine{NumIters: 1, NumCarbons: 60},
+			want: Nuclei{nC, nO},
+		},
+		{
+			name: "carbon",
+			e:    Engine{NumIters: 1, NumCarbons: 100},
+			want: Nuclei{nC},
+		},
+		{
+			name: "oxygen",
+			e:    Engine{NumIters: 1, NumCarbons: 0},
+			want: Nuclei{nO},
+		},
+		{
+			name: "sources",
+			e: Engine{
+				NumIters:   1,
+				NumCarbons: 100,
+				Sources: []Source{
+					{Every: 10, N: 5, Composition: []Abundance{{Nucleus: nMg, Weight: 1}, {Nucleus: nSi}}},
+					{Every: 10, N: 5},
+				},
+			},
+			want: Nuclei{nC, nMg},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.e.Start()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tc.e.InitialNuclei(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("invalid initial nuclei: got=%v, want=%v", got, tc.want)
+			}
+		})
+	}
+}
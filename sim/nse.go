@@ -0,0 +1,212 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	kBoltzmann = 0.08617333262 // Boltzmann constant, in MeV/GK
+	hbarC      = 197.3269804   // reduced Planck constant times c, in MeV.fm
+	massU      = 931.49410242  // atomic mass unit, in MeV
+	avogadro   = 6.02214076e23 // Avogadro constant, in 1/mol
+)
+
+// Species describes a nuclide taking part in nuclear statistical equilibrium.
+type Species struct {
+	Nucleus Nucleus
+	Binding float64 // total binding energy, in MeV
+	G       float64 // partition function (2J+1 of the ground state)
+}
+
+// NSESpecies is the default list of nuclides used to compute
+// nuclear statistical equilibria.
+var NSESpecies = []Species{
+	nseSpecies(Nucleus{A: 1, Z: 0}, 2),
+	nseSpecies(Nucleus{A: 1, Z: 1}, 2),
+	nseSpecies(Nucleus{A: 4, Z: 2}, 1),
+	nseSpecies(nC, 1),
+	nseSpecies(nO, 1),
+	nseSpecies(Nucleus{A: 20, Z: 10}, 1),
+	nseSpecies(nMg, 1),
+	nseSpecies(nSi, 1),
+	nseSpecies(nS, 1),
+	nseSpecies(nAr, 1),
+	nseSpecies(nCa, 1),
+	nseSpecies(nTi, 1),
+	nseSpecies(nCr, 1),
+	nseSpecies(Nucleus{A: 52, Z: 24}, 1),
+	nseSpecies(nFe, 1),
+	nseSpecies(Nucleus{A: 54, Z: 26}, 1),
+	nseSpecies(Nucleus{A: 55, Z: 25}, 6),
+	nseSpecies(Nucleus{A: 55, Z: 26}, 4),
+	nseSpecies(Nucleus{A: 55, Z: 27}, 8),
+	nseSpecies(Nucleus{A: 56, Z: 26}, 1),
+	nseSpecies(Nucleus{A: 56, Z: 27}, 9),
+	nseSpecies(nNi, 1),
+	nseSpecies(Nucleus{A: 57, Z: 28}, 4),
+	nseSpecies(Nucleus{A: 58, Z: 26}, 1),
+	nseSpecies(Nucleus{A: 58, Z: 28}, 1),
+}
+
+func nseSpecies(n Nucleus, g float64) Species {
+	return Species{Nucleus: n, Binding: BindingEnergy(n), G: g}
+}
+
+// NSE describes the thermodynamic conditions of a nuclear statistical
+// equilibrium.
+//
+// In equilibrium, the mass fraction of a nuclide with Z protons and N neutrons
+// is given by the Saha equation:
+//
+//	X = G A^(5/2) / 2^A (rho Na λ^3)^(A-1) exp(B/kT) Xp^Z Xn^N
+//
+// where λ is the thermal wavelength of a nucleon and Xp and Xn are the mass
+// fractions of free protons and neutrons, set by mass conservation and
+// by the electron fraction.
+type NSE struct {
+	T9      float64   // temperature, in GK
+	Rho     float64   // density, in g/cm^3
+	Ye      float64   // electron fraction
+	Species []Species // nuclides in equilibrium (zero: NSESpecies)
+}
+
+// MassFraction is the mass fraction of a nuclide.
+type MassFraction struct {
+	Nucleus Nucleus
+	X       float64
+}
+
+// Composition is the composition of matter in nuclear statistical equilibrium,
+// sorted by mass number.
+type Composition []MassFraction
+
+// Lookup returns the mass fraction of the nucleus n.
+func (c Composition) Lookup(n Nucleus) float64 {
+	for _, x := range c {
+		if x.Nucleus == n {
+			return x.X
+		}
+	}
+	return 0
+}
+
+func (c Composition) String() string {
+	o := []string{}
+	o = append(o, fmt.Sprintf("composition of %d nuclides (mass fractions):", len(c)))
+	for _, x := range c {
+		o = append(o, fmt.Sprintf("%v: %g", x.Nucleus, x.X))
+	}
+	return strings.Join(o, "\n")
+}
+
+// Solve returns the equilibrium mass fractions of the species.
+// Solve uses a Newton-Raphson method on the chemical potentials of
+// free protons and neutrons.
+func (nse NSE) Solve() (Composition, error) {
+	species := nse.Species
+	if species == nil {
+		species = NSESpecies
+	}
+	switch {
+	case len(species) == 0:
+		return nil, fmt.Errorf("sim: no species in NSE")
+	case nse.T9 <= 0:
+		return nil, fmt.Errorf("sim: invalid NSE temperature T9=%g", nse.T9)
+	case nse.Rho <= 0:
+		return nil, fmt.Errorf("sim: invalid NSE density rho=%g", nse.Rho)
+	}
+
+	minYe, maxYe := math.Inf(+1), math.Inf(-1)
+	for _, s := range species {
+		ye := float64(s.Nucleus.Z) / float64(s.Nucleus.A)
+		minYe = math.Min(minYe, ye)
+		maxYe = math.Max(maxYe, ye)
+	}
+	if nse.Ye <= minYe || nse.Ye >= maxYe {
+		return nil, fmt.Errorf(
+			"sim: NSE electron fraction Ye=%g outside of the species range (%g, %g)",
+			nse.Ye, minYe, maxYe,
+		)
+	}
+
+	kt := kBoltzmann * nse.T9
+	lambda := math.Sqrt(2*math.Pi*hbarC*hbarC/(massU*kt)) * 1e-13 // in cm
+	lrho := math.Log(nse.Rho * avogadro * lambda * lambda * lambda)
+
+	// ln X = c + Z*u + N*v, with u and v the logarithms of
+	// the mass fractions of free protons and neutrons.
+	c := make([]float64, len(species))
+	for i, s := range species {
+		a := float64(s.Nucleus.A)
+		c[i] = math.Log(s.G) + 2.5*math.Log(a) - a*math.Ln2 + (a-1)*lrho + s.Binding/kt
+	}
+
+	// start where the largest mass fraction is 1.
+	w := math.Inf(+1)
+	for i, s := range species {
+		w = math.Min(w, -c[i]/float64(s.Nucleus.A))
+	}
+	u, v := w, w
+
+	const (
+		maxIter = 500
+		maxStep = 2.0
+		tol     = 1e-10
+	)
+
+	x := make([]float64, len(species))
+	for iter := 0; iter < maxIter; iter++ {
+		var (
+			sx, sxz, sxn   float64 // sum of X, Z*X and N*X
+			sy, syzz, syzn float64 // sum of Z*Y, Z*Z*Y and Z*N*Y
+		)
+		for i, s := range species {
+			z := float64(s.Nucleus.Z)
+			n := float64(s.Nucleus.N())
+			x[i] = math.Exp(c[i] + z*u + n*v)
+			y := x[i] / float64(s.Nucleus.A)
+			sx += x[i]
+			sxz += z * x[i]
+			sxn += n * x[i]
+			sy += z * y
+			syzz += z * z * y
+			syzn += z * n * y
+		}
+
+		f1 := math.Log(sx)
+		f2 := math.Log(sy) - math.Log(nse.Ye)
+		if math.Abs(f1) < tol && math.Abs(f2) < tol {
+			comp := make(Composition, len(species))
+			for i, s := range species {
+				comp[i] = MassFraction{Nucleus: s.Nucleus, X: x[i]}
+			}
+			sort.SliceStable(comp, func(i, j int) bool {
+				return nucleusLess(comp[i].Nucleus, comp[j].Nucleus)
+			})
+			return comp, nil
+		}
+
+		j11, j12 := sxz/sx, sxn/sx
+		j21, j22 := syzz/sy, syzn/sy
+		det := j11*j22 - j12*j21
+		if det == 0 || math.IsNaN(det) {
+			break
+		}
+		du := -(j22*f1 - j12*f2) / det
+		dv := -(j11*f2 - j21*f1) / det
+		if m := math.Max(math.Abs(du), math.Abs(dv)); m > maxStep {
+			du *= maxStep / m
+			dv *= maxStep / m
+		}
+		u += du
+		v += dv
+	}
+
+	return nil, fmt.Errorf(
+		"sim: NSE did not converge (T9=%g, rho=%g, Ye=%g)",
+		nse.T9, nse.Rho, nse.Ye,
+	)
+}
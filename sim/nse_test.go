@@ -0,0 +1,94 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+var (
+	nNeutron = Nucleus{A: 1, Z: 0}
+	nProton  = Nucleus{A: 1, Z: 1}
+)
+
+func TestNSEConservation(t *testing.T) {
+	for _, nse := range []NSE{
+		{T9: 4, Rho: 1e9, Ye: 0.5},
+		{T9: 5, Rho: 1e7, Ye: 0.5},
+		{T9: 5, Rho: 1e8, Ye: 0.5},
+		{T9: 5, Rho: 1e9, Ye: 0.46},
+		{T9: 8, Rho: 1e8, Ye: 0.48},
+		{T9: 15, Rho: 1e8, Ye: 0.5},
+		{T9: 30, Rho: 1e9, Ye: 0.45},
+	} {
+		comp, err := nse.Solve()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sum, ye float64
+		for _, x := range comp {
+			if x.X < 0 {
+				t.Errorf("%+v: negative mass fraction for %v: %g", nse, x.Nucleus, x.X)
+			}
+			sum += x.X
+			ye += float64(x.Nucleus.Z) / float64(x.Nucleus.A) * x.X
+		}
+		if math.Abs(sum-1) > 1e-8 {
+			t.Errorf("%+v: invalid sum of mass fractions: got=%v, want=1", nse, sum)
+		}
+		if math.Abs(ye-nse.Ye) > 1e-8 {
+			t.Errorf("%+v: invalid electron fraction: got=%v, want=%v", nse, ye, nse.Ye)
+		}
+	}
+}
+
+func TestNSEFreeNucleons(t *testing.T) {
+	for _, nse := range []NSE{
+		{T9: 20, Rho: 1e7, Ye: 0.5},
+		{T9: 30, Rho: 1e9, Ye: 0.45},
+	} {
+		comp, err := nse.Solve()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x := comp.Lookup(nNeutron) + comp.Lookup(nProton); x < 0.9 {
+			t.Errorf("%+v: invalid mass fraction of free nucleons: %g\n%v", nse, x, comp)
+		}
+	}
+}
+
+func TestNSENickel(t *testing.T) {
+	nse := NSE{T9: 5, Rho: 1e8, Ye: 0.5}
+	comp, err := nse.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ni := comp.Lookup(nNi)
+	if ni < 0.5 {
+		t.Errorf("invalid mass fraction of %v: %g", nNi, ni)
+	}
+	for _, x := range comp {
+		if x.Nucleus != nNi && x.X >= ni {
+			t.Errorf("%v more abundant than %v: %g >= %g", x.Nucleus, nNi, x.X, ni)
+		}
+	}
+}
+
+func TestNSEInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		nse NSE
+		err string
+	}{
+		{NSE{T9: 0, Rho: 1e8, Ye: 0.5}, "sim: invalid NSE temperature T9=0"},
+		{NSE{T9: 5, Rho: -1, Ye: 0.5}, "sim: invalid NSE density rho=-1"},
+		{NSE{T9: 5, Rho: 1e8, Ye: 1}, "sim: NSE electron fraction Ye=1 outside of the species range (0, 1)"},
+		{NSE{T9: 5, Rho: 1e8, Ye: 0.5, Species: []Species{}}, "sim: no species in NSE"},
+	} {
+		_, err := tc.nse.Solve()
+		if err == nil {
+			t.Fatalf("%+v: expected an error", tc.nse)
+		}
+		if got := err.Error(); got != tc.err {
+			t.Fatalf("invalid error:\ngot= %v\nwant=%v", got, tc.err)
+		}
+	}
+}
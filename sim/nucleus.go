@@ -1,13 +1,19 @@
 package sim
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
 
 // Nucleus models a standard model nucleus.
 // It holds the mass number A and the atomic number Z
 // of this nucleus.
+// Its JSON encoding is part of the output formats.
 type Nucleus struct {
-	A int // mass number
-	Z int // atomic number
+	A int `json:"A"` // mass number
+	Z int `json:"Z"` // atomic number
 }
 
 // N returns the number of nucleons
@@ -15,18 +21,137 @@ func (n Nucleus) N() int {
 	return n.A - n.Z
 }
 
+// Name returns the conventional name of the nucleus, e.g. "56Ni".
+func (n Nucleus) Name() string {
+	return fmt.Sprintf("%d%s", n.A, n.Symbol())
+}
+
+// Symbol returns the chemical symbol of the element of the nucleus.
+func (n Nucleus) Symbol() string {
+	if n.Z < 0 || n.Z >= len(elements) {
+		return fmt.Sprintf("Z%d", n.Z)
+	}
+	return elements[n.Z]
+}
+
+// ParseNucleus parses a nucleus name, such as "56Ni" or "ni56".
+// Symbols are case insensitive.
+// The nucleons "n" and "p" and the hydrogen isotopes "d" and "t" are
+// also recognized.
+func ParseNucleus(s string) (Nucleus, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "n":
+		return Nucleus{A: 1, Z: 0}, nil
+	case "p":
+		return Nucleus{A: 1, Z: 1}, nil
+	case "d":
+		return Nucleus{A: 2, Z: 1}, nil
+	case "t":
+		return Nucleus{A: 3, Z: 1}, nil
+	}
+
+	str := strings.TrimSpace(s)
+	i := strings.IndexFunc(str, unicode.IsLetter)
+	j := strings.LastIndexFunc(str, unicode.IsLetter)
+	if i < 0 {
+		return Nucleus{}, fmt.Errorf("sim: invalid nucleus name %q", s)
+	}
+	var sym, num string
+	switch {
+	case i == 0:
+		sym, num = str[:j+1], str[j+1:]
+	case j == len(str)-1:
+		num, sym = str[:i], str[i:]
+	default:
+		return Nucleus{}, fmt.Errorf("sim: invalid nucleus name %q", s)
+	}
+
+	a, err := strconv.Atoi(num)
+	if err != nil {
+		return Nucleus{}, fmt.Errorf("sim: invalid mass number in nucleus name %q", s)
+	}
+	for z, elem := range elements {
+		if strings.EqualFold(elem, sym) {
+			return Nucleus{A: a, Z: z}, nil
+		}
+	}
+	return Nucleus{}, fmt.Errorf("sim: invalid element symbol in nucleus name %q", s)
+}
+
+// elements holds the chemical symbols of all elements, indexed by
+// atomic number.
+var elements = []string{
+	"n", "H", "He", "Li", "Be", "B", "C", "N", "O", "F", "Ne",
+	"Na", "Mg", "Al", "Si", "P", "S", "Cl", "Ar", "K", "Ca",
+	"Sc", "Ti", "V", "Cr", "Mn", "Fe", "Co", "Ni", "Cu", "Zn",
+	"Ga", "Ge", "As", "Se", "Br", "Kr", "Rb", "Sr", "Y", "Zr",
+	"Nb", "Mo", "Tc", "Ru", "Rh", "Pd", "Ag", "Cd", "In", "Sn",
+	"Sb", "Te", "I", "Xe", "Cs", "Ba", "La", "Ce", "Pr", "Nd",
+	"Pm", "Sm", "Eu", "Gd", "Tb", "Dy", "Ho", "Er", "Tm", "Yb",
+	"Lu", "Hf", "Ta", "W", "Re", "Os", "Ir", "Pt", "Au", "Hg",
+	"Tl", "Pb", "Bi", "Po", "At", "Rn", "Fr", "Ra", "Ac", "Th",
+	"Pa", "U", "Np", "Pu", "Am", "Cm", "Bk", "Cf", "Es", "Fm",
+	"Md", "No", "Lr", "Rf", "Db", "Sg", "Bh", "Hs", "Mt", "Ds",
+	"Rg", "Cn", "Nh", "Fl", "Mc", "Lv", "Ts", "Og",
+}
+
 func (n Nucleus) String() string {
 	return fmt.Sprintf("Nucleus{A: %2d, Z:%2d}", n.A, n.Z)
 }
 
 // Fuse returns the product of the fusion of two nuclei n1 and n2,
 // or false if the fusion is not physically possible.
+// Fuse uses the DefaultChart.
 func Fuse(n1, n2 Nucleus) (Nucleus, bool) {
+	return DefaultChart.Fuse(n1, n2)
+}
+
+// DefaultChart stops fusion at the iron peak.
+var DefaultChart = Chart{MaxA: 56}
+
+// Chart describes the region of the chart of nuclides
+// fusion products are allowed to populate.
+// A zero limit means no limit.
+type Chart struct {
+	MaxA  int     // maximum mass number of a fusion product
+	MaxZ  int     // maximum atomic number of a fusion product
+	MinNZ float64 // minimum N/Z ratio of a fusion product
+	MaxNZ float64 // maximum N/Z ratio of a fusion product
+}
+
+// Allows returns whether the nucleus n lies within the region
+// of the chart of nuclides described by c.
+func (c Chart) Allows(n Nucleus) bool {
+	if c.MaxA > 0 && n.A > c.MaxA {
+		return false
+	}
+	if c.MaxZ > 0 && n.Z > c.MaxZ {
+		return false
+	}
+	if c.MinNZ <= 0 && c.MaxNZ <= 0 {
+		return true
+	}
+	if n.Z <= 0 {
+		return false
+	}
+	nz := float64(n.N()) / float64(n.Z)
+	if c.MinNZ > 0 && nz < c.MinNZ {
+		return false
+	}
+	if c.MaxNZ > 0 && nz > c.MaxNZ {
+		return false
+	}
+	return true
+}
+
+// Fuse returns the product of the fusion of two nuclei n1 and n2,
+// or false if the product lies outside the region allowed by c.
+func (c Chart) Fuse(n1, n2 Nucleus) (Nucleus, bool) {
 	o := Nucleus{
 		A: n1.A + n2.A,
 		Z: n1.Z + n2.Z,
 	}
-	if o.A <= 56 {
+	if c.Allows(o) {
 		return o, true
 	}
 	return Nucleus{}, false
@@ -52,36 +177,62 @@ var (
 	nFe = Nucleus{A: 52, Z: 26}
 	nNi = Nucleus{A: 56, Z: 28}
 
-	// xsects is all the cross-sections that a sim.Engine can handle
-	xsects = map[pair]float64{
-		pair{nC, nC}:   0.8315672884,
-		pair{nO, nC}:   1,
-		pair{nO, nO}:   0.9872126376,
-		pair{nMg, nC}:  0.97267664,
-		pair{nMg, nO}:  0.965386457,
-		pair{nMg, nMg}: 0.8924961757,
-		pair{nSi, nC}:  0.7969537454,
-		pair{nSi, nO}:  0.6755141681,
-		pair{nSi, nMg}: 0.7702788102,
-		pair{nSi, nSi}: 0.6517919696,
-		pair{nS, nC}:   0.6883015304,
-		pair{nS, nO}:   0.7202932946,
-		pair{nS, nMg}:  0.8330120436,
-		pair{nAr, nC}:  0.7513868241,
-		pair{nAr, nO}:  0.7976021702,
-		pair{nCa, nC}:  0.8048923532,
-		pair{nCa, nO}:  0.8548382242,
-		pair{nTi, nC}:  0.9762778016,
+	// xsects is all the cross-sections of the Standard fusion model
+	xsects = map[Pair]float64{
+		Pair{nC, nC}:   0.8315672884,
+		Pair{nO, nC}:   1,
+		Pair{nO, nO}:   0.9872126376,
+		Pair{nMg, nC}:  0.97267664,
+		Pair{nMg, nO}:  0.965386457,
+		Pair{nMg, nMg}: 0.8924961757,
+		Pair{nSi, nC}:  0.7969537454,
+		Pair{nSi, nO}:  0.6755141681,
+		Pair{nSi, nMg}: 0.7702788102,
+		Pair{nSi, nSi}: 0.6517919696,
+		Pair{nS, nC}:   0.6883015304,
+		Pair{nS, nO}:   0.7202932946,
+		Pair{nS, nMg}:  0.8330120436,
+		Pair{nAr, nC}:  0.7513868241,
+		Pair{nAr, nO}:  0.7976021702,
+		Pair{nCa, nC}:  0.8048923532,
+		Pair{nCa, nO}:  0.8548382242,
+		Pair{nTi, nC}:  0.9762778016,
 	}
 )
 
-type pair [2]Nucleus
+// Pair is a pair of reacting nuclei.
+type Pair [2]Nucleus
+
+// sorted returns the pair with its lightest nucleus first.
+func (p Pair) sorted() Pair {
+	if nucleusLess(p[1], p[0]) {
+		return Pair{p[1], p[0]}
+	}
+	return p
+}
+
+// nucleusLess orders nuclei by increasing mass and atomic numbers.
+func nucleusLess(ni, nj Nucleus) bool {
+	return ni.A < nj.A || (ni.A == nj.A && ni.Z < nj.Z)
+}
+
+// pairLess orders pairs by increasing mass and atomic numbers.
+func pairLess(pi, pj Pair) bool {
+	if pi[0] != pj[0] {
+		return nucleusLess(pi[0], pj[0])
+	}
+	return nucleusLess(pi[1], pj[1])
+}
+
+func (p Pair) String() string {
+	return fmt.Sprintf("%v + %v", p[0], p[1])
+}
 
 func init() {
 	for k, v := range xsects {
 		if k[0] == k[1] {
 			continue
 		}
-		xsects[pair{k[1], k[0]}] = v
+		xsects[Pair{k[1], k[0]}] = v
 	}
 }
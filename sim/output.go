@@ -0,0 +1,155 @@
+package sim
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Output receives the output of a simulation run.
+// An Engine calls WriteHeader once, then WriteRecord for the initial state of
+// the population and at the end of each iteration, then WriteFlux and Flush.
+type Output interface {
+	// WriteHeader writes the metadata of the run.
+	WriteHeader(meta Metadata) error
+
+	// WriteRecord writes the state of the population at the end of
+	// an iteration.
+	WriteRecord(rec Record) error
+
+	// WriteFlux writes the integrated reaction flux of the run.
+	WriteFlux(rf ReactionFlux) error
+
+	// Flush writes any buffered data to the underlying io.Writer.
+	Flush() error
+}
+
+// RecordRepeater is implemented by outputs that can write the record of
+// an unchanged population more efficiently than with WriteRecord.
+// An Engine in rejection-free mode uses it for the rejected attempts
+// preceding a fusion.
+type RecordRepeater interface {
+	// RepeatRecord writes the last written record again, with the
+	// iteration number iter and the acceptance statistics acc.
+	RepeatRecord(iter int, acc AcceptanceStats) error
+}
+
+// CSVWriter writes the output of a simulation as a CSV file with '#'
+// comments and ';' separators:
+//   - the metadata header (see HeaderCSV),
+//   - a comment line with the names of the columns,
+//   - one record per iteration,
+//   - the reaction flux trailer (see HeaderFlux).
+//
+// CSVWriter implements Output and RecordRepeater.
+type CSVWriter struct {
+	w      *bufio.Writer
+	meta   Metadata
+	last   Record // last written record
+	masses []byte // formatted masses of the last written record
+	line   []byte
+}
+
+// NewCSVWriter returns a new CSVWriter writing to w.
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: bufio.NewWriter(w)}
+}
+
+// WriteHeader implements Output.
+func (cw *CSVWriter) WriteHeader(meta Metadata) error {
+	cw.meta = meta
+
+	hdr, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(cw.w, "%v%v\n", string(HeaderCSV), string(hdr))
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(meta.Columns))
+	for i, col := range meta.Columns {
+		names[i] = col.Name
+	}
+	_, err = fmt.Fprintf(cw.w, "# %s\n", strings.Join(names, ";"))
+	return err
+}
+
+// WriteRecord implements Output.
+func (cw *CSVWriter) WriteRecord(rec Record) error {
+	cw.masses = cw.masses[:0]
+	for i, n := range cw.meta.Config.Population {
+		if i > 0 {
+			cw.masses = append(cw.masses, ';')
+		}
+		cw.masses = strconv.AppendInt(cw.masses, int64(rec.Mass(n)), 10)
+	}
+	cw.last = rec
+	return cw.writeLine()
+}
+
+// RepeatRecord implements RecordRepeater.
+func (cw *CSVWriter) RepeatRecord(iter int, acc AcceptanceStats) error {
+	cw.last.Iter = iter
+	cw.last.Acceptance = acc
+	return cw.writeLine()
+}
+
+// writeLine writes the last record, with its formatted masses.
+func (cw *CSVWriter) writeLine() error {
+	var (
+		e    = &cw.meta.Config
+		rec  = cw.last
+		line = append(cw.line[:0], cw.masses...)
+	)
+	sep := func() {
+		if len(line) > 0 {
+			line = append(line, ';')
+		}
+	}
+	if e.Acceptance {
+		for _, v := range []int{
+			rec.Acceptance.Attempts,
+			rec.Acceptance.Self,
+			rec.Acceptance.NoFusion,
+			rec.Acceptance.Unknown,
+			rec.Acceptance.Rejected,
+			rec.Size,
+		} {
+			sep()
+			line = strconv.AppendInt(line, int64(v), 10)
+		}
+	}
+	if e.Heating.Enabled() {
+		sep()
+		line = strconv.AppendFloat(line, rec.T9, 'g', 6, 64)
+	}
+	if e.Capture.Enabled() {
+		sep()
+		line = strconv.AppendFloat(line, rec.Ye, 'g', 6, 64)
+	}
+	line = append(line, '\n')
+	cw.line = line
+	_, err := cw.w.Write(line)
+	return err
+}
+
+// WriteFlux implements Output.
+func (cw *CSVWriter) WriteFlux(rf ReactionFlux) error {
+	trailer, err := json.Marshal(rf)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(cw.w, "%s%s\n", HeaderFlux, trailer)
+	return err
+}
+
+// Flush implements Output.
+func (cw *CSVWriter) Flush() error {
+	return cw.w.Flush()
+}
@@ -0,0 +1,106 @@
+package reaclib
+
+import (
+	"fmt"
+
+	"github.com/astrogo/snfusion/sim"
+)
+
+// ModelName is the name under which the fusion model reading the
+// REACLIB library stored in the file sim.Engine.ModelFile is registered.
+const ModelName = "reaclib"
+
+func init() {
+	sim.Register(ModelName, func(e *sim.Engine) (sim.FusionModel, error) {
+		if e.ModelFile == "" {
+			return nil, fmt.Errorf("reaclib: no REACLIB file for fusion model %q", ModelName)
+		}
+		lib, err := Open(e.ModelFile)
+		if err != nil {
+			return nil, err
+		}
+		chart := sim.DefaultChart
+		if e.Chart != nil {
+			chart = *e.Chart
+		}
+		return NewModel(lib, chart, e.Conditions)
+	})
+}
+
+// Model is a fusion model whose probabilities are derived from the rates
+// of a REACLIB library.
+// Products are the sum of the fusing nuclei, restricted to Chart.
+// The probability for a pair of nuclei to fuse is the total rate of
+// their reactions, rho*N_A<σv>/(1+δij), times Scale, clamped to 1: pairs
+// of identical nuclei (e.g. 12C+12C) are counted once.
+type Model struct {
+	Library *Library
+	Chart   sim.Chart
+	Scale   float64 // fusion probability per unit rate, in s
+	T9      float64 // temperature used when the conditions have none, in GK
+}
+
+// NewModel returns a fusion model using the rates of lib, scaled such that
+// the fastest reaction under the conditions c happens with probability 1.
+func NewModel(lib *Library, chart sim.Chart, c sim.Conditions) (Model, error) {
+	if lib.pairs == nil {
+		// index the reactions of libraries not read by Parse.
+		l := *lib
+		l.index()
+		lib = &l
+	}
+	m := Model{Library: lib, Chart: chart, T9: c.T9}
+	if c.T9 <= 0 {
+		return m, fmt.Errorf("reaclib: invalid temperature T9=%g", c.T9)
+	}
+
+	var max float64
+	for _, p := range lib.Pairs() {
+		if _, ok := chart.Fuse(p[0], p[1]); !ok {
+			continue
+		}
+		if v := m.rate(p[0], p[1], c); v > max {
+			max = v
+		}
+	}
+	if max == 0 {
+		return m, fmt.Errorf("reaclib: no fusion reaction in library")
+	}
+	m.Scale = 1 / max
+	return m, nil
+}
+
+// Products implements sim.FusionModel.
+func (m Model) Products(ni, nj sim.Nucleus) (sim.Nucleus, bool) {
+	return m.Chart.Fuse(ni, nj)
+}
+
+// Probability implements sim.FusionModel.
+func (m Model) Probability(ni, nj sim.Nucleus, c sim.Conditions) (float64, error) {
+	p := m.Scale * m.rate(ni, nj, c)
+	if p > 1 {
+		p = 1
+	}
+	return p, nil
+}
+
+// Tabulated implements sim.Tabulator.
+func (m Model) Tabulated(ni, nj sim.Nucleus) bool {
+	return len(m.Library.reactions(ni, nj)) > 0
+}
+
+func (m Model) rate(ni, nj sim.Nucleus, c sim.Conditions) float64 {
+	t9 := c.T9
+	if t9 <= 0 {
+		t9 = m.T9
+	}
+	rho := c.Rho
+	if rho <= 0 {
+		rho = 1
+	}
+	v, _ := m.Library.Rate(ni, nj, t9)
+	if ni == nj {
+		v /= 2
+	}
+	return rho * v
+}
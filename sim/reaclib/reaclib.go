@@ -0,0 +1,312 @@
+// Package reaclib reads thermonuclear reaction rates in the REACLIB format
+// of the JINA REACLIB database, and turns them into fusion models for
+// the snfusion simulation engine.
+//
+// A REACLIB file is a list of sets, each made of a header line holding the
+// nuclides taking part in the reaction, a label, flags and the Q-value,
+// followed by two lines holding the 7 parameters of the rate fit:
+//
+//	λ = exp(a0 + a1/T9 + a2/T9^(1/3) + a3*T9^(1/3) + a4*T9 + a5*T9^(5/3) + a6*ln(T9))
+//
+// Sets are grouped into chapters, given by the number of reactants and
+// products of their reactions.
+// Both the REACLIB1 format (a chapter line starting each chapter) and the
+// REACLIB2 format (a chapter line starting each set) are supported.
+package reaclib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/astrogo/snfusion/sim"
+)
+
+// chapters holds the number of reactants and products of the reactions
+// of each chapter.
+var chapters = [...][2]int{
+	1:  {1, 1},
+	2:  {1, 2},
+	3:  {1, 3},
+	4:  {2, 1},
+	5:  {2, 2},
+	6:  {2, 3},
+	7:  {2, 4},
+	8:  {3, 1},
+	9:  {3, 2},
+	10: {4, 2},
+	11: {1, 4},
+}
+
+// Set is a single fit of a reaction rate.
+type Set struct {
+	Chapter   int
+	Reactants []sim.Nucleus
+	Products  []sim.Nucleus
+	Label     string     // source of the rate
+	Flag      byte       // 'n': non-resonant, 'r': resonant, 'w': weak, 's': spontaneous
+	Reverse   bool       // whether the rate is derived from the forward rate by detailed balance
+	Q         float64    // Q-value of the reaction, in MeV
+	A         [7]float64 // fit parameters
+}
+
+// Rate returns the rate of the set at the temperature t9, in GK.
+// Rates of two-body reactions are N_A<σv>, in cm^3/mol/s.
+func (s Set) Rate(t9 float64) float64 {
+	t13 := math.Cbrt(t9)
+	v := s.A[0] +
+		s.A[1]/t9 +
+		s.A[2]/t13 +
+		s.A[3]*t13 +
+		s.A[4]*t9 +
+		s.A[5]*t9*t13*t13 +
+		s.A[6]*math.Log(t9)
+	return math.Exp(v)
+}
+
+func (s Set) String() string {
+	str := func(ns []sim.Nucleus) string {
+		o := make([]string, len(ns))
+		for i, n := range ns {
+			o[i] = n.Name()
+		}
+		return strings.Join(o, " + ")
+	}
+	return fmt.Sprintf("%s -> %s [%s]", str(s.Reactants), str(s.Products), s.Label)
+}
+
+// Library is a collection of REACLIB sets.
+// The two-body reactions of libraries read by Parse are indexed by pair of
+// reactants: their Sets should not be modified.
+type Library struct {
+	Sets []Set
+
+	pairs map[sim.Pair][]*Set // forward two-body reactions, by sorted pair of reactants
+}
+
+// Open reads the REACLIB library stored in the named file.
+func Open(fname string) (*Library, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads a REACLIB library from r.
+func Parse(r io.Reader) (*Library, error) {
+	var (
+		lib     Library
+		chapter int
+		lines   []string
+		lineno  int
+		start   int
+	)
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		lineno++
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		if len(lines) == 0 {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if ch, err := strconv.Atoi(strings.TrimSpace(line)); err == nil {
+				if ch <= 0 || ch >= len(chapters) {
+					return nil, fmt.Errorf("reaclib: line %d: invalid chapter %d", lineno, ch)
+				}
+				chapter = ch
+				continue
+			}
+			start = lineno
+		}
+		lines = append(lines, line)
+		if len(lines) < 3 {
+			continue
+		}
+		if chapter == 0 {
+			return nil, fmt.Errorf("reaclib: line %d: set without chapter", start)
+		}
+		set, err := parseSet(chapter, lines)
+		if err != nil {
+			return nil, fmt.Errorf("reaclib: line %d: %v", start, err)
+		}
+		lib.Sets = append(lib.Sets, set)
+		lines = lines[:0]
+	}
+	err := sc.Err()
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) != 0 {
+		return nil, fmt.Errorf("reaclib: line %d: truncated set", start)
+	}
+
+	lib.index()
+	return &lib, nil
+}
+
+// index indexes the forward two-body reactions of the library by pair
+// of reactants.
+func (lib *Library) index() {
+	lib.pairs = make(map[sim.Pair][]*Set)
+	for i := range lib.Sets {
+		s := &lib.Sets[i]
+		if s.Reverse || len(s.Reactants) != 2 {
+			continue
+		}
+		k := key(s.Reactants[0], s.Reactants[1])
+		lib.pairs[k] = append(lib.pairs[k], s)
+	}
+}
+
+// reactions returns the forward two-body reactions between the nuclei
+// ni and nj.
+func (lib *Library) reactions(ni, nj sim.Nucleus) []*Set {
+	if lib.pairs != nil {
+		return lib.pairs[key(ni, nj)]
+	}
+	var sets []*Set
+	for i := range lib.Sets {
+		if lib.Sets[i].fuses(ni, nj) {
+			sets = append(sets, &lib.Sets[i])
+		}
+	}
+	return sets
+}
+
+// parseSet parses the 3 lines of a set of the provided chapter.
+func parseSet(chapter int, lines []string) (Set, error) {
+	set := Set{Chapter: chapter}
+	hdr := pad(lines[0], 74)
+
+	var names []string
+	for i := 0; i < 6; i++ {
+		name := strings.TrimSpace(hdr[5+5*i : 10+5*i])
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	nr := chapters[chapter][0]
+	if len(names) < nr+1 {
+		return set, fmt.Errorf("invalid number of nuclides (%d) for chapter %d", len(names), chapter)
+	}
+	for i, name := range names {
+		n, err := parseNucleus(name)
+		if err != nil {
+			return set, err
+		}
+		if i < nr {
+			set.Reactants = append(set.Reactants, n)
+		} else {
+			set.Products = append(set.Products, n)
+		}
+	}
+
+	set.Label = strings.TrimSpace(hdr[43:47])
+	set.Flag = hdr[47]
+	set.Reverse = hdr[48] == 'v'
+	q, err := strconv.ParseFloat(strings.TrimSpace(hdr[52:64]), 64)
+	if err != nil {
+		return set, fmt.Errorf("invalid Q-value: %v", err)
+	}
+	set.Q = q
+
+	for i := range set.A {
+		line := pad(lines[1+i/4], 52)
+		j := 13 * (i % 4)
+		v, err := strconv.ParseFloat(strings.TrimSpace(line[j:j+13]), 64)
+		if err != nil {
+			return set, fmt.Errorf("invalid parameter a%d: %v", i, err)
+		}
+		set.A[i] = v
+	}
+
+	return set, nil
+}
+
+// parseNucleus parses a REACLIB nuclide name.
+// The isomers of 26Al are identified with its ground state.
+func parseNucleus(name string) (sim.Nucleus, error) {
+	switch name {
+	case "al-6", "al*6":
+		return sim.Nucleus{A: 26, Z: 13}, nil
+	}
+	return sim.ParseNucleus(name)
+}
+
+func pad(s string, n int) string {
+	if len(s) >= n {
+		return s
+	}
+	return s + strings.Repeat(" ", n-len(s))
+}
+
+// Rate returns the total rate N_A<σv> of the two-body reactions between
+// the nuclei ni and nj at the temperature t9, in GK, and whether the
+// library holds such a reaction.
+// Reverse rates are ignored.
+func (lib *Library) Rate(ni, nj sim.Nucleus, t9 float64) (float64, bool) {
+	sets := lib.reactions(ni, nj)
+	var rate float64
+	for _, s := range sets {
+		rate += s.Rate(t9)
+	}
+	return rate, len(sets) > 0
+}
+
+// fuses returns whether the set is a forward two-body reaction between
+// the nuclei ni and nj.
+func (s Set) fuses(ni, nj sim.Nucleus) bool {
+	if s.Reverse || len(s.Reactants) != 2 {
+		return false
+	}
+	r := s.Reactants
+	return (r[0] == ni && r[1] == nj) || (r[0] == nj && r[1] == ni)
+}
+
+// Pairs returns the sorted list of pairs of nuclei with a forward two-body
+// reaction in the library.
+func (lib *Library) Pairs() []sim.Pair {
+	set := make(map[sim.Pair]struct{})
+	for _, s := range lib.Sets {
+		if s.Reverse || len(s.Reactants) != 2 {
+			continue
+		}
+		set[key(s.Reactants[0], s.Reactants[1])] = struct{}{}
+	}
+	pairs := make([]sim.Pair, 0, len(set))
+	for p := range set {
+		pairs = append(pairs, p)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		pi, pj := pairs[i], pairs[j]
+		if pi[0] != pj[0] {
+			return lessNucleus(pi[0], pj[0])
+		}
+		return lessNucleus(pi[1], pj[1])
+	})
+	return pairs
+}
+
+// key returns the pair of the nuclei ni and nj, with its lightest
+// nucleus first.
+func key(ni, nj sim.Nucleus) sim.Pair {
+	if lessNucleus(nj, ni) {
+		return sim.Pair{nj, ni}
+	}
+	return sim.Pair{ni, nj}
+}
+
+func lessNucleus(ni, nj sim.Nucleus) bool {
+	if ni.A != nj.A {
+		return ni.A < nj.A
+	}
+	return ni.Z < nj.Z
+}
@@ -0,0 +1,375 @@
+package reaclib
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/astrogo/snfusion/sim"
+)
+
+var (
+	nP   = sim.Nucleus{A: 1, Z: 1}
+	nHe  = sim.Nucleus{A: 4, Z: 2}
+	nC   = sim.Nucleus{A: 12, Z: 6}
+	nO   = sim.Nucleus{A: 16, Z: 8}
+	nNe  = sim.Nucleus{A: 20, Z: 10}
+	nAl  = sim.Nucleus{A: 26, Z: 13}
+	nMg  = sim.Nucleus{A: 24, Z: 12}
+	nSi  = sim.Nucleus{A: 28, Z: 14}
+	nNeu = sim.Nucleus{A: 1, Z: 0}
+)
+
+func rate(a [7]float64, t9 float64) float64 {
+	return math.Exp(a[0] +
+		a[1]*math.Pow(t9, -1) +
+		a[2]*math.Pow(t9, -1./3) +
+		a[3]*math.Pow(t9, 1./3) +
+		a[4]*t9 +
+		a[5]*math.Pow(t9, 5./3) +
+		a[6]*math.Log(t9))
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-12*math.Max(math.Abs(a), math.Abs(b))
+}
+
+func TestOpen(t *testing.T) {
+	lib, err := Open("testdata/sample.reaclib")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := len(lib.Sets), 9; got != want {
+		t.Fatalf("invalid number of sets: got=%d, want=%d", got, want)
+	}
+
+	for _, tc := range []struct {
+		i         int
+		chapter   int
+		reactants []sim.Nucleus
+		products  []sim.Nucleus
+		label     string
+		flag      byte
+		reverse   bool
+		q         float64
+	}{
+		{0, 1, []sim.Nucleus{nNeu}, []sim.Nucleus{nP}, "wc12", 'w', false, 0.782300},
+		{1, 1, []sim.Nucleus{nAl}, []sim.Nucleus{{A: 26, Z: 12}}, "wc17", 'w', false, 4.00410},
+		{3, 4, []sim.Nucleus{nHe, nC}, []sim.Nucleus{nO}, "nac2", 'r', false, 7.16192},
+		{5, 5, []sim.Nucleus{nC, nC}, []sim.Nucleus{nHe, nNe}, "cf88", 'r', false, 4.621},
+		{7, 5, []sim.Nucleus{nHe, nNe}, []sim.Nucleus{nC, nC}, "cf88", 'r', true, -4.621},
+		{8, 8, []sim.Nucleus{nHe, nHe, nHe}, []sim.Nucleus{nC}, "fy05", 'r', false, 7.275},
+	} {
+		s := lib.Sets[tc.i]
+		if s.Chapter != tc.chapter {
+			t.Errorf("set %d: invalid chapter: got=%d, want=%d", tc.i, s.Chapter, tc.chapter)
+		}
+		if !equal(s.Reactants, tc.reactants) {
+			t.Errorf("set %d: invalid reactants: got=%v, want=%v", tc.i, s.Reactants, tc.reactants)
+		}
+		if !equal(s.Products, tc.products) {
+			t.Errorf("set %d: invalid products: got=%v, want=%v", tc.i, s.Products, tc.products)
+		}
+		if s.Label != tc.label || s.Flag != tc.flag || s.Reverse != tc.reverse {
+			t.Errorf("set %d: invalid flags: got=(%q, %c, %v), want=(%q, %c, %v)",
+				tc.i, s.Label, s.Flag, s.Reverse, tc.label, tc.flag, tc.reverse,
+			)
+		}
+		if !near(s.Q, tc.q) {
+			t.Errorf("set %d: invalid Q-value: got=%v, want=%v", tc.i, s.Q, tc.q)
+		}
+	}
+
+	want := [7]float64{6.965260e+01, -1.392540e+00, 5.891280e+01, -1.482730e+02, 9.083240e+00, -5.410410e-01, 7.035540e+01}
+	if got := lib.Sets[3].A; got != want {
+		t.Errorf("invalid parameters:\ngot= %v\nwant=%v", got, want)
+	}
+}
+
+func TestRate(t *testing.T) {
+	lib, err := Open("testdata/sample.reaclib")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, t9 := range []float64{0.1, 0.5, 1, 3, 10} {
+		s := lib.Sets[2]
+		if got, want := s.Rate(t9), rate(s.A, t9); !near(got, want) {
+			t.Errorf("T9=%v: invalid set rate: got=%v, want=%v", t9, got, want)
+		}
+
+		// resonant and non-resonant contributions add up.
+		got, ok := lib.Rate(nC, nHe, t9)
+		if !ok {
+			t.Fatalf("T9=%v: missing 12C+4He rate", t9)
+		}
+		want := rate(lib.Sets[2].A, t9) + rate(lib.Sets[3].A, t9)
+		if !near(got, want) {
+			t.Errorf("T9=%v: invalid 12C+4He rate: got=%v, want=%v", t9, got, want)
+		}
+
+		// all exit channels add up, reverse rates are ignored.
+		got, ok = lib.Rate(nC, nC, t9)
+		if !ok {
+			t.Fatalf("T9=%v: missing 12C+12C rate", t9)
+		}
+		want = rate(lib.Sets[5].A, t9) + rate(lib.Sets[6].A, t9)
+		if !near(got, want) {
+			t.Errorf("T9=%v: invalid 12C+12C rate: got=%v, want=%v", t9, got, want)
+		}
+	}
+
+	// libraries not read by Parse are not indexed.
+	raw := &Library{Sets: lib.Sets}
+	for _, p := range [][2]sim.Nucleus{{nC, nHe}, {nHe, nC}, {nC, nC}, {nO, nO}} {
+		got, gok := raw.Rate(p[0], p[1], 2)
+		want, wok := lib.Rate(p[0], p[1], 2)
+		if got != want || gok != wok {
+			t.Errorf("%v+%v: invalid rate of a library without index: got=(%v, %v), want=(%v, %v)",
+				p[0], p[1], got, gok, want, wok)
+		}
+	}
+
+	if _, ok := lib.Rate(nHe, nNe, 1); ok {
+		t.Errorf("reverse rate used as a fusion rate")
+	}
+	if _, ok := lib.Rate(nO, nO, 1); ok {
+		t.Errorf("unexpected 16O+16O rate")
+	}
+
+	pairs := lib.Pairs()
+	want := []sim.Pair{{nHe, nC}, {nHe, nO}, {nC, nC}}
+	if len(pairs) != len(want) {
+		t.Fatalf("invalid pairs: got=%v, want=%v", pairs, want)
+	}
+	for i := range pairs {
+		if pairs[i] != want[i] {
+			t.Fatalf("invalid pairs: got=%v, want=%v", pairs, want)
+		}
+	}
+}
+
+func TestModel(t *testing.T) {
+	lib, err := Open("testdata/sample.reaclib")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := sim.Conditions{T9: 3, Rho: 1e7}
+	m, err := NewModel(lib, sim.DefaultChart, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var max float64
+	for _, p := range [][2]sim.Nucleus{{nC, nC}, {nHe, nC}, {nO, nHe}} {
+		v, err := m.Probability(p[0], p[1], c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v <= 0 || v > 1 {
+			t.Errorf("invalid probability for %v+%v: %v", p[0], p[1], v)
+		}
+		max = math.Max(max, v)
+		if !m.Tabulated(p[0], p[1]) {
+			t.Errorf("%v+%v should be tabulated", p[0], p[1])
+		}
+	}
+	if !near(max, 1) {
+		t.Errorf("invalid maximal probability: got=%v, want=1", max)
+	}
+
+	v, err := m.Probability(nO, nO, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 0 || m.Tabulated(nO, nO) {
+		t.Errorf("invalid 16O+16O probability: %v", v)
+	}
+
+	// fusion is faster when hotter.
+	lo, _ := m.Probability(nC, nC, sim.Conditions{T9: 1, Rho: 1e7})
+	hi, _ := m.Probability(nC, nC, sim.Conditions{T9: 2, Rho: 1e7})
+	if lo >= hi {
+		t.Errorf("12C+12C probability does not increase with temperature: %v >= %v", lo, hi)
+	}
+
+	if o, ok := m.Products(nO, nC); !ok || o != nSi {
+		t.Errorf("invalid products of 16O+12C: %v (%v)", o, ok)
+	}
+	if o, ok := m.Products(nC, nC); !ok || o != nMg {
+		t.Errorf("invalid products of 12C+12C: %v (%v)", o, ok)
+	}
+}
+
+func TestModelIdentical(t *testing.T) {
+	lib, err := Open("testdata/sample.reaclib")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := sim.Conditions{T9: 0.5, Rho: 1e7}
+	m, err := NewModel(lib, sim.DefaultChart, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		ni, nj sim.Nucleus
+		factor float64 // 1/(1+δij)
+	}{
+		{nC, nC, 0.5},
+		{nHe, nC, 1},
+		{nC, nHe, 1},
+	} {
+		got, err := m.Probability(tc.ni, tc.nj, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rate, _ := lib.Rate(tc.ni, tc.nj, c.T9)
+		want := m.Scale * c.Rho * rate * tc.factor
+		if want >= 1 {
+			t.Fatalf("%v+%v: saturated probability", tc.ni, tc.nj)
+		}
+		if !near(got, want) {
+			t.Errorf("%v+%v: invalid probability: got=%v, want=%v", tc.ni, tc.nj, got, want)
+		}
+	}
+
+	// a library not read by Parse gives the same model.
+	raw, err := NewModel(&Library{Sets: lib.Sets}, sim.DefaultChart, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw.Scale != m.Scale || !raw.Tabulated(nC, nC) || raw.Tabulated(nO, nO) {
+		t.Errorf("invalid model of a library without index: scale=%v, want=%v", raw.Scale, m.Scale)
+	}
+}
+
+func TestParseReaclib1(t *testing.T) {
+	// REACLIB1 files have a single chapter line per chapter.
+	const src = `4
+
+       he4  c12  o16                       nac2n     7.16192e+00
+ 2.546340e+02-1.840970e+00 1.034110e+02-4.205670e+02
+ 6.408740e+01-1.246240e+01 1.373030e+02
+       he4  c12  o16                       nac2r     7.16192e+00
+ 6.965260e+01-1.392540e+00 5.891280e+01-1.482730e+02
+ 9.083240e+00-5.410410e-01 7.035540e+01
+5
+
+       c12  c12  he4 ne20                  cf88r     4.62100e+00
+ 6.128630e+01 0.000000e+00-8.416500e+01-1.566270e+00
+-7.360840e-02-7.279700e-02-6.666670e-01
+`
+	lib, err := Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(lib.Sets), 3; got != want {
+		t.Fatalf("invalid number of sets: got=%d, want=%d", got, want)
+	}
+	for i, want := range []int{4, 4, 5} {
+		if got := lib.Sets[i].Chapter; got != want {
+			t.Errorf("set %d: invalid chapter: got=%d, want=%d", i, got, want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		src  string
+		err  string
+	}{
+		{
+			name: "no-chapter",
+			src: `       he4  c12  o16                       nac2n     7.16192e+00
+ 2.546340e+02-1.840970e+00 1.034110e+02-4.205670e+02
+ 6.408740e+01-1.246240e+01 1.373030e+02
+`,
+			err: "reaclib: line 1: set without chapter",
+		},
+		{
+			name: "bad-chapter",
+			src:  "12\n",
+			err:  "reaclib: line 1: invalid chapter 12",
+		},
+		{
+			name: "truncated",
+			src: `4
+       he4  c12  o16                       nac2n     7.16192e+00
+ 2.546340e+02-1.840970e+00 1.034110e+02-4.205670e+02
+`,
+			err: "reaclib: line 2: truncated set",
+		},
+		{
+			name: "bad-nuclide",
+			src: `4
+       he4  c12 xx16                       nac2n     7.16192e+00
+ 2.546340e+02-1.840970e+00 1.034110e+02-4.205670e+02
+ 6.408740e+01-1.246240e+01 1.373030e+02
+`,
+			err: `reaclib: line 2: sim: invalid element symbol in nucleus name "xx16"`,
+		},
+		{
+			name: "bad-parameter",
+			src: `4
+       he4  c12  o16                       nac2n     7.16192e+00
+ 2.546340e+02-1.840970e+00 1.034110e+02-4.205670e+02
+ 6.408740e+01-1.246240e+01 1.3730x0e+02
+`,
+			err: `reaclib: line 2: invalid parameter a6: strconv.ParseFloat: parsing "1.3730x0e+02": invalid syntax`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tc.src))
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if got, want := err.Error(), tc.err; got != want {
+				t.Fatalf("invalid error:\ngot= %v\nwant=%v", got, want)
+			}
+		})
+	}
+}
+
+func equal(a, b []sim.Nucleus) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRegisteredModel(t *testing.T) {
+	e := sim.Engine{
+		Model:      ModelName,
+		ModelFile:  "testdata/sample.reaclib",
+		Conditions: sim.Conditions{T9: 3, Rho: 1e7},
+	}
+	m, err := sim.NewModel(e.Model, &e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.(Model); !ok {
+		t.Fatalf("invalid fusion model type %T", m)
+	}
+	v, err := m.Probability(nC, nC, e.Conditions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v <= 0 || v > 1 {
+		t.Errorf("invalid probability for %v+%v: %v", nC, nC, v)
+	}
+
+	e.ModelFile = ""
+	_, err = sim.NewModel(e.Model, &e)
+	if err == nil {
+		t.Fatalf("expected an error without REACLIB file")
+	}
+}
@@ -0,0 +1,246 @@
+package sim
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Sensitivity holds the normalized sensitivity coefficients of the final
+// masses of the nuclei of a population to the cross-section of a pair:
+//
+//	S = d ln(M) / d ln(σ)
+type Sensitivity struct {
+	Pair   Pair
+	Coeffs []float64 // sensitivity coefficient of each nucleus of the population
+	Errs   []float64 // statistical uncertainty of each coefficient
+}
+
+// SensitivityAnalysis describes the sensitivity analysis of the yields of
+// a simulation to the cross-sections of the Standard fusion model.
+//
+// Each cross-section is scaled up and down by a factor 1±Delta, and both
+// perturbed configurations are run with the same ensemble of seeds
+// (Engine.Seed, Engine.Seed+1, ...) to cancel out most of the Monte Carlo noise.
+//
+// Fusion attempts accept probabilities up to 1: when a perturbed
+// cross-section would exceed 1, all the cross-sections of all the runs are
+// divided by a common factor and the time scales of the simulations are
+// stretched by that factor (see stretch), which leaves the evolution of
+// the population unchanged.
+type SensitivityAnalysis struct {
+	Engine  Engine  // configuration of the simulations
+	Delta   float64 // relative perturbation of the cross-sections (zero: 0.1)
+	Members int     // number of simulations per configuration (zero: 10)
+}
+
+// Run runs the sensitivity analysis and returns the sensitivity
+// coefficients of the cross-sections of all the tabulated pairs, sorted by pair.
+func (sa SensitivityAnalysis) Run() ([]Sensitivity, error) {
+	if sa.Delta == 0 {
+		sa.Delta = 0.1
+	}
+	if sa.Members == 0 {
+		sa.Members = 10
+	}
+	if sa.Delta <= 0 || sa.Delta >= 1 {
+		return nil, fmt.Errorf("sim: invalid sensitivity perturbation %g", sa.Delta)
+	}
+	if sa.Engine.Model != "" && sa.Engine.Model != DefaultModel {
+		return nil, fmt.Errorf("sim: sensitivity analysis needs the %q fusion model", DefaultModel)
+	}
+
+	table := CrossSections()
+	pairs := make([]Pair, 0, len(table))
+	for p := range table {
+		pairs = append(pairs, p)
+	}
+	sortPairs(pairs)
+
+	pop := sa.Engine.Population
+	if pop == nil {
+		pop = Population
+	}
+
+	norm := 1.0
+	for _, xs := range table {
+		norm = math.Max(norm, xs*(1+sa.Delta))
+	}
+	tmpl := sa.Engine
+	stretch(&tmpl, norm)
+
+	// masses[i][k][0|1] holds the final masses of the down/up configurations
+	// of the k-th member, for the i-th pair.
+	masses := make([][][2][]float64, len(pairs))
+	for i := range masses {
+		masses[i] = make([][2][]float64, sa.Members)
+	}
+
+	err := parallel(len(pairs)*sa.Members*2, func(job int) error {
+		i, k, sign := job/(2*sa.Members), (job/2)%sa.Members, job%2
+		f := 1 - sa.Delta
+		if sign == 1 {
+			f = 1 + sa.Delta
+		}
+		counts, err := runMember(tmpl, perturb(table, pairs[i], f, norm), sa.Engine.Seed+int64(k))
+		if err != nil {
+			return err
+		}
+		m := make([]float64, len(pop))
+		for j, n := range pop {
+			m[j] = float64(counts[n] * n.A)
+		}
+		masses[i][k][sign] = m
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dlog := math.Log((1 + sa.Delta) / (1 - sa.Delta))
+	sens := make([]Sensitivity, len(pairs))
+	for i, p := range pairs {
+		s := Sensitivity{
+			Pair:   p,
+			Coeffs: make([]float64, len(pop)),
+			Errs:   make([]float64, len(pop)),
+		}
+		for j := range pop {
+			var mean float64
+			for _, m := range masses[i] {
+				mean += m[0][j] + m[1][j]
+			}
+			mean /= float64(2 * sa.Members)
+			if mean == 0 {
+				continue
+			}
+			coeffs := make([]float64, sa.Members)
+			for k, m := range masses[i] {
+				coeffs[k] = (m[1][j] - m[0][j]) / (mean * dlog)
+			}
+			c, std := meanStd(coeffs)
+			s.Coeffs[j] = c
+			s.Errs[j] = std / math.Sqrt(float64(sa.Members))
+		}
+		sens[i] = s
+	}
+
+	return sens, nil
+}
+
+// perturb returns a copy of the cross-sections of table, with the one of
+// the pair p scaled by f, all divided by norm.
+func perturb(table map[Pair]float64, p Pair, f, norm float64) map[Pair]float64 {
+	o := make(map[Pair]float64, len(table))
+	for pair, xs := range table {
+		o[pair] = xs / norm
+	}
+	o[p] *= f
+	return o
+}
+
+// stretch stretches the time scales of the engine e by the factor f, to
+// run with fusion probabilities divided by f: the number of iterations and
+// the periods of the source and sink terms are multiplied by f, while
+// the cooling, electron capture and fallback probabilities are divided by f.
+func stretch(e *Engine, f float64) {
+	if f == 1 {
+		return
+	}
+	scale := func(n int) int {
+		return int(math.Round(float64(n) * f))
+	}
+	e.NumIters = scale(e.NumIters)
+	e.Sources = append([]Source(nil), e.Sources...)
+	for i := range e.Sources {
+		e.Sources[i].Every = scale(e.Sources[i].Every)
+	}
+	e.Sinks = append([]Sink(nil), e.Sinks...)
+	for i := range e.Sinks {
+		e.Sinks[i].Every = scale(e.Sinks[i].Every)
+	}
+	e.Heating.Cooling /= f
+	e.Capture.Rate /= f
+	norm := e.Fallback.Norm
+	if norm == 0 {
+		norm = 1
+	}
+	e.Fallback.Norm = norm / f
+}
+
+// runMember runs a simulation configured after tmpl, with the provided
+// seed and, if not nil, the provided cross-sections for the Standard
+// fusion model, and returns the final population.
+func runMember(tmpl Engine, table map[Pair]float64, seed int64) (map[Nucleus]int, error) {
+	e := tmpl
+	e.Seed = seed
+	e.SetLogger(log.New(ioutil.Discard, "", 0))
+	if table != nil {
+		e.Model = DefaultModel
+		m, err := NewModel(DefaultModel, &e)
+		if err != nil {
+			return nil, err
+		}
+		std := m.(Standard)
+		std.Table = table
+		e.SetModel(std)
+	}
+
+	err := e.Run(ioutil.Discard)
+	if err != nil {
+		return nil, err
+	}
+	return e.Counts(), nil
+}
+
+// parallel calls fn(0), ..., fn(n-1) concurrently and returns the first
+// error encountered.
+func parallel(n int, fn func(i int) error) error {
+	idx := make(chan int)
+	errs := make(chan error, n)
+
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				err := fn(i)
+				if err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		idx <- i
+	}
+	close(idx)
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// meanStd returns the mean and the standard deviation of the values.
+func meanStd(vs []float64) (mean, std float64) {
+	for _, v := range vs {
+		mean += v
+	}
+	mean /= float64(len(vs))
+	if len(vs) < 2 {
+		return mean, 0
+	}
+	var v2 float64
+	for _, v := range vs {
+		v2 += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(v2 / float64(len(vs)-1))
+}
+
+func sortPairs(pairs []Pair) {
+	sort.Slice(pairs, func(i, j int) bool { return pairLess(pairs[i], pairs[j]) })
+}
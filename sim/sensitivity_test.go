@@ -0,0 +1,85 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPerturbSaturated(t *testing.T) {
+	const delta = 0.1
+	table := CrossSections()
+	pair := Pair{nC, nO}
+	if table[pair] != 1 {
+		t.Fatalf("invalid 12C+16O cross-section: %v", table[pair])
+	}
+
+	norm := 1 + delta
+	up := perturb(table, pair, 1+delta, norm)
+	dn := perturb(table, pair, 1-delta, norm)
+	for p, xs := range table {
+		if up[p] > 1 || dn[p] > 1 {
+			t.Errorf("%v: saturated probability (up=%v, down=%v)", p, up[p], dn[p])
+		}
+		if p == pair {
+			continue
+		}
+		if up[p] != dn[p] || math.Abs(up[p]*norm-xs) > 1e-12 {
+			t.Errorf("%v: invalid unperturbed probability (up=%v, down=%v, want=%v)", p, up[p], dn[p], xs/norm)
+		}
+	}
+	if got, want := up[pair]/dn[pair], (1+delta)/(1-delta); math.Abs(got-want) > 1e-12 {
+		t.Errorf("invalid perturbation of 12C+16O: got=%v, want=%v", got, want)
+	}
+	if table[pair] != 1 {
+		t.Errorf("perturb modified its input table")
+	}
+}
+
+func TestStretch(t *testing.T) {
+	e := Engine{
+		NumIters: 1000,
+		Sources:  []Source{{Every: 100, N: 10}},
+		Sinks:    []Sink{{Every: 50, Fraction: 0.1}},
+		Heating:  Heating{HeatCapacity: 1, Cooling: 0.01},
+		Capture:  Capture{Rate: 0.5},
+	}
+	tmpl := e
+	stretch(&e, 2)
+
+	if e.NumIters != 2000 {
+		t.Errorf("invalid number of iterations: %d", e.NumIters)
+	}
+	if e.Sources[0].Every != 200 || e.Sinks[0].Every != 100 {
+		t.Errorf("invalid source/sink periods: %d, %d", e.Sources[0].Every, e.Sinks[0].Every)
+	}
+	if tmpl.Sources[0].Every != 100 || tmpl.Sinks[0].Every != 50 {
+		t.Errorf("stretch modified the periods of the template engine")
+	}
+	if e.Heating.Cooling != 0.005 || e.Capture.Rate != 0.25 || e.Fallback.Norm != 0.5 {
+		t.Errorf("invalid rates: cooling=%v, capture=%v, fallback=%v",
+			e.Heating.Cooling, e.Capture.Rate, e.Fallback.Norm,
+		)
+	}
+}
+
+func TestSensitivitySaturated(t *testing.T) {
+	sa := SensitivityAnalysis{
+		Engine:  Engine{NumIters: 2000, NumCarbons: 60, Seed: 1234},
+		Members: 4,
+	}
+	sens, err := sa.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range sens {
+		if s.Pair != (Pair{nC, nO}) {
+			continue
+		}
+		// raising 12C+16O burns carbon faster.
+		if s.Coeffs[0] >= 0 {
+			t.Errorf("invalid sensitivity of 12C to 12C+16O: %v", s.Coeffs[0])
+		}
+		return
+	}
+	t.Fatalf("no sensitivity for 12C+16O")
+}
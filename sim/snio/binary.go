@@ -0,0 +1,616 @@
+package snio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/astrogo/snfusion/sim"
+)
+
+// Binary snfusion files store the records of a simulation column by column,
+// in chunks of consecutive records. All numbers are little-endian.
+//
+// The file starts with a fixed header:
+//
+//	magic      [4]byte "SNFB"
+//	version    uint16
+//	reserved   uint16
+//	chunk size uint32  maximum number of records per chunk
+//	ncols      uint32  number of output columns (see sim.Metadata)
+//	meta size  uint32
+//	meta       [meta size]byte, the JSON encoded sim.Metadata
+//
+// followed by the chunks. Each chunk starts with:
+//
+//	nrows      uint32  number of records of the chunk
+//	first iter uint64  iteration of the first record of the chunk
+//	sizes      [ncols]uint32, size in bytes of each column
+//
+// followed by the data of each column.
+// Integer columns are encoded as zig-zag varints of the difference with
+// the previous value of the chunk, floating point columns as varints of
+// the XOR of the IEEE 754 representation with the previous value.
+// The first value of a chunk is encoded against zero, so that each chunk may
+// be decoded on its own.
+//
+// The last chunk is followed by:
+//
+//	end        uint32  0
+//	flux size  uint32
+//	flux       [flux size]byte, the JSON encoded sim.ReactionFlux
+//	nchunks    uint32
+//	index      [nchunks]{first iter uint64; nrows uint32; offset uint64}
+//	index pos  uint64  offset of nchunks in the file
+//	magic      [4]byte "SNFB"
+const (
+	binMagic     = "SNFB"
+	binVersion   = 1
+	binChunkSize = 4096
+	binFooter    = 12 // size of the footer: index pos and magic
+)
+
+// BinaryWriter writes the output of a simulation in the binary snfusion
+// format, a compact columnar format allowing random access to ranges of
+// iterations and to single columns (see BinaryReader).
+// BinaryWriter implements sim.Output.
+type BinaryWriter struct {
+	w     *bufio.Writer
+	n     int64 // number of bytes written
+	meta  sim.Metadata
+	forms []byte
+
+	first  int            // iteration of the first record of the current chunk
+	nrows  int            // number of records of the current chunk
+	cols   []bytes.Buffer // encoded columns of the current chunk
+	prev   []uint64       // previous values of the columns
+	words  []uint64
+	index  []binChunk
+	varint [binary.MaxVarintLen64]byte
+}
+
+// binChunk is an entry of the index of a binary file.
+type binChunk struct {
+	first  int   // iteration of the first record
+	nrows  int   // number of records
+	offset int64 // offset of the chunk in the file
+}
+
+// NewBinaryWriter returns a new BinaryWriter writing to w.
+func NewBinaryWriter(w io.Writer) *BinaryWriter {
+	return &BinaryWriter{w: bufio.NewWriter(w)}
+}
+
+// WriteHeader implements sim.Output.
+func (bw *BinaryWriter) WriteHeader(meta sim.Metadata) error {
+	bw.meta = meta
+	bw.forms = columnForms(&meta.Config)
+	bw.cols = make([]bytes.Buffer, len(bw.forms))
+	bw.prev = make([]uint64, len(bw.forms))
+
+	js, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	hdr := []byte(binMagic)
+	hdr = binary.LittleEndian.AppendUint16(hdr, binVersion)
+	hdr = binary.LittleEndian.AppendUint16(hdr, 0)
+	hdr = binary.LittleEndian.AppendUint32(hdr, binChunkSize)
+	hdr = binary.LittleEndian.AppendUint32(hdr, uint32(len(bw.forms)))
+	hdr = binary.LittleEndian.AppendUint32(hdr, uint32(len(js)))
+	err = bw.write(hdr)
+	if err != nil {
+		return err
+	}
+	return bw.write(js)
+}
+
+// WriteRecord implements sim.Output.
+func (bw *BinaryWriter) WriteRecord(rec sim.Record) error {
+	if bw.nrows == 0 {
+		bw.first = rec.Iter
+		for i := range bw.prev {
+			bw.prev[i] = 0
+		}
+	}
+	bw.words = columnValues(bw.words[:0], &bw.meta.Config, rec)
+	for i, v := range bw.words {
+		var n int
+		switch bw.forms[i] {
+		case 'D':
+			n = binary.PutUvarint(bw.varint[:], v^bw.prev[i])
+		default:
+			n = binary.PutVarint(bw.varint[:], int64(v-bw.prev[i]))
+		}
+		bw.cols[i].Write(bw.varint[:n])
+		bw.prev[i] = v
+	}
+	bw.nrows++
+	if bw.nrows == binChunkSize {
+		return bw.flushChunk()
+	}
+	return nil
+}
+
+// WriteFlux implements sim.Output.
+// WriteFlux writes the last chunk, the reaction flux and the index.
+func (bw *BinaryWriter) WriteFlux(rf sim.ReactionFlux) error {
+	err := bw.flushChunk()
+	if err != nil {
+		return err
+	}
+
+	js, err := json.Marshal(rf)
+	if err != nil {
+		return err
+	}
+	buf := binary.LittleEndian.AppendUint32(nil, 0)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(js)))
+	buf = append(buf, js...)
+	err = bw.write(buf)
+	if err != nil {
+		return err
+	}
+
+	pos := bw.n
+	buf = binary.LittleEndian.AppendUint32(buf[:0], uint32(len(bw.index)))
+	for _, c := range bw.index {
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(c.first))
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(c.nrows))
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(c.offset))
+	}
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(pos))
+	buf = append(buf, binMagic...)
+	return bw.write(buf)
+}
+
+// Flush implements sim.Output.
+func (bw *BinaryWriter) Flush() error {
+	return bw.w.Flush()
+}
+
+// flushChunk writes the current chunk, if any.
+func (bw *BinaryWriter) flushChunk() error {
+	if bw.nrows == 0 {
+		return nil
+	}
+	bw.index = append(bw.index, binChunk{first: bw.first, nrows: bw.nrows, offset: bw.n})
+
+	hdr := binary.LittleEndian.AppendUint32(nil, uint32(bw.nrows))
+	hdr = binary.LittleEndian.AppendUint64(hdr, uint64(bw.first))
+	for i := range bw.cols {
+		hdr = binary.LittleEndian.AppendUint32(hdr, uint32(bw.cols[i].Len()))
+	}
+	err := bw.write(hdr)
+	if err != nil {
+		return err
+	}
+	for i := range bw.cols {
+		err = bw.write(bw.cols[i].Bytes())
+		if err != nil {
+			return err
+		}
+		bw.cols[i].Reset()
+	}
+	bw.nrows = 0
+	return nil
+}
+
+func (bw *BinaryWriter) write(p []byte) error {
+	n, err := bw.w.Write(p)
+	bw.n += int64(n)
+	return err
+}
+
+// decodeColumn decodes the n values of a column of format form.
+func decodeColumn(dst []uint64, data []byte, form byte, n int) ([]uint64, error) {
+	var prev uint64
+	for i := 0; i < n; i++ {
+		var (
+			v uint64
+			k int
+		)
+		switch form {
+		case 'D':
+			v, k = binary.Uvarint(data)
+			v ^= prev
+		default:
+			var d int64
+			d, k = binary.Varint(data)
+			v = prev + uint64(d)
+		}
+		if k <= 0 {
+			return dst, fmt.Errorf("snio: invalid binary column data")
+		}
+		data = data[k:]
+		dst = append(dst, v)
+		prev = v
+	}
+	return dst, nil
+}
+
+// readBinaryHeader reads the fixed header of a binary file.
+func readBinaryHeader(r io.Reader) (sim.Metadata, error) {
+	var meta sim.Metadata
+	hdr := make([]byte, 20)
+	_, err := io.ReadFull(r, hdr)
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return meta, ErrTruncated
+		}
+		return meta, err
+	}
+	if string(hdr[:4]) != binMagic {
+		return meta, ErrNoHeader
+	}
+	if v := binary.LittleEndian.Uint16(hdr[4:]); v != binVersion {
+		return meta, fmt.Errorf("snio: unsupported binary format version %d", v)
+	}
+	ncols := int(binary.LittleEndian.Uint32(hdr[12:]))
+	js := make([]byte, binary.LittleEndian.Uint32(hdr[16:]))
+	_, err = io.ReadFull(r, js)
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return meta, ErrTruncated
+		}
+		return meta, err
+	}
+	meta, err = sim.ParseMetadata(js)
+	if err != nil {
+		return meta, fmt.Errorf("snio: invalid binary metadata: %w", err)
+	}
+	if ncols != len(meta.Columns) {
+		return meta, fmt.Errorf(
+			"snio: invalid number of binary columns (got=%d, want=%d)",
+			ncols, len(meta.Columns),
+		)
+	}
+	return meta, nil
+}
+
+// binReader reads sequentially the records of a binary file.
+type binReader struct {
+	r     io.Reader
+	forms []byte
+	cols  [][]uint64 // decoded columns of the current chunk
+	first int        // iteration of the first record of the current chunk
+	nrows int        // number of records of the current chunk
+	row   int        // next record of the current chunk
+}
+
+// next returns the next record of the file.
+func (br *binReader) next(r *Reader) (Record, error) {
+	if br.row == br.nrows {
+		err := br.chunk(r)
+		if err != nil {
+			return Record{}, err
+		}
+	}
+	i := br.row
+	rec := r.record(
+		func(j int) int { return int(int64(br.cols[j][i])) },
+		func(j int) float64 { return math.Float64frombits(br.cols[j][i]) },
+	)
+	rec.Iter = br.first + i
+	br.row++
+	r.iter++
+	return rec, nil
+}
+
+// chunk reads the next chunk, or the reaction flux and io.EOF after
+// the last one.
+func (br *binReader) chunk(r *Reader) error {
+	hdr := make([]byte, 12+4*len(br.forms))
+	err := br.read(hdr[:4])
+	if err != nil {
+		return err
+	}
+	nrows := int(binary.LittleEndian.Uint32(hdr))
+	if nrows == 0 {
+		err = br.read(hdr[:4])
+		if err != nil {
+			return err
+		}
+		js := make([]byte, binary.LittleEndian.Uint32(hdr))
+		err = br.read(js)
+		if err != nil {
+			return err
+		}
+		err = json.Unmarshal(js, &r.flux)
+		if err != nil {
+			return fmt.Errorf("snio: invalid reaction flux: %w", err)
+		}
+		return io.EOF
+	}
+
+	err = br.read(hdr[4:])
+	if err != nil {
+		return err
+	}
+	br.first = int(binary.LittleEndian.Uint64(hdr[4:]))
+	br.nrows = nrows
+	br.row = 0
+	for i, form := range br.forms {
+		data := make([]byte, binary.LittleEndian.Uint32(hdr[12+4*i:]))
+		err = br.read(data)
+		if err != nil {
+			return err
+		}
+		br.cols[i], err = decodeColumn(br.cols[i][:0], data, form, nrows)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (br *binReader) read(p []byte) error {
+	_, err := io.ReadFull(br.r, p)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrTruncated
+	}
+	return err
+}
+
+// BinaryReader gives random access to the records of a binary snfusion file
+// (see BinaryWriter), reading only the chunks and columns it needs.
+// Records are identified by their iteration number, starting at 0 for
+// the initial state of the population.
+type BinaryReader struct {
+	Meta   sim.Metadata // metadata of the file
+	Engine sim.Engine   // configuration of the simulation (same as Meta.Config.Engine())
+
+	r     io.ReaderAt
+	forms []byte
+	index []binChunk
+	flux  sim.ReactionFlux
+	rec   Reader // decodes records
+}
+
+// NewBinaryReader returns a reader of the binary snfusion file r,
+// of size bytes, after having read its metadata and its index.
+// Compressed files do not allow random access: they should be read
+// sequentially with a Reader.
+func NewBinaryReader(r io.ReaderAt, size int64) (*BinaryReader, error) {
+	magic := make([]byte, len(gzipMagic))
+	if _, err := r.ReadAt(magic, 0); err == nil && string(magic) == gzipMagic {
+		return nil, fmt.Errorf("snio: random access to a compressed binary file")
+	}
+
+	meta, err := readBinaryHeader(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return nil, err
+	}
+
+	br := &BinaryReader{
+		Meta:   meta,
+		Engine: meta.Config.Engine(),
+		r:      r,
+		forms:  columnForms(&meta.Config),
+	}
+	br.rec.Meta = meta
+	br.rec.Engine = meta.Config.Engine()
+
+	if size < binFooter {
+		return nil, ErrTruncated
+	}
+	footer := make([]byte, binFooter)
+	_, err = r.ReadAt(footer, size-binFooter)
+	if err != nil {
+		return nil, err
+	}
+	if string(footer[8:]) != binMagic {
+		return nil, ErrTruncated
+	}
+	pos := int64(binary.LittleEndian.Uint64(footer))
+	if pos < 0 || pos > size-binFooter-4 {
+		return nil, fmt.Errorf("snio: invalid binary index offset %d", pos)
+	}
+	idx := make([]byte, size-binFooter-pos)
+	_, err = r.ReadAt(idx, pos)
+	if err != nil {
+		return nil, err
+	}
+	nchunks := int(binary.LittleEndian.Uint32(idx))
+	if len(idx) != 4+20*nchunks {
+		return nil, fmt.Errorf("snio: invalid binary index")
+	}
+	br.index = make([]binChunk, nchunks)
+	for i := range br.index {
+		buf := idx[4+20*i:]
+		br.index[i] = binChunk{
+			first:  int(binary.LittleEndian.Uint64(buf)),
+			nrows:  int(binary.LittleEndian.Uint32(buf[8:])),
+			offset: int64(binary.LittleEndian.Uint64(buf[12:])),
+		}
+	}
+
+	// the reaction flux sits right before the index.
+	end := pos
+	if nchunks > 0 {
+		last := br.index[nchunks-1]
+		sizes, err := br.sizes(last)
+		if err != nil {
+			return nil, err
+		}
+		end = last.offset + int64(12+4*len(br.forms))
+		for _, n := range sizes {
+			end += int64(n)
+		}
+	}
+	if pos-end < 8 {
+		return nil, fmt.Errorf("snio: invalid binary reaction flux")
+	}
+	js := make([]byte, pos-end-8)
+	_, err = r.ReadAt(js, end+8)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(js, &br.flux)
+	if err != nil {
+		return nil, fmt.Errorf("snio: invalid reaction flux: %w", err)
+	}
+
+	return br, nil
+}
+
+// Len returns the number of records of the file.
+func (br *BinaryReader) Len() int {
+	n := 0
+	for _, c := range br.index {
+		n += c.nrows
+	}
+	return n
+}
+
+// Flux returns the reaction flux of the file.
+func (br *BinaryReader) Flux() sim.ReactionFlux {
+	return br.flux
+}
+
+// Records returns the records of the iterations [beg, end).
+func (br *BinaryReader) Records(beg, end int) ([]Record, error) {
+	cols := make([][]uint64, len(br.forms))
+	for i := range cols {
+		var err error
+		cols[i], err = br.column(i, beg, end)
+		if err != nil {
+			return nil, err
+		}
+	}
+	recs := make([]Record, 0, end-beg)
+	for k := 0; k < end-beg; k++ {
+		rec := br.rec.record(
+			func(j int) int { return int(int64(cols[j][k])) },
+			func(j int) float64 { return math.Float64frombits(cols[j][k]) },
+		)
+		rec.Iter = beg + k
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+// Column returns the values of the named column (see sim.Metadata) for
+// the iterations [beg, end).
+// Integer columns are returned as exact float64 values.
+func (br *BinaryReader) Column(name string, beg, end int) ([]float64, error) {
+	icol := -1
+	for i, col := range br.Meta.Columns {
+		if col.Name == name {
+			icol = i
+			break
+		}
+	}
+	if icol < 0 {
+		return nil, fmt.Errorf("snio: no column %q", name)
+	}
+	words, err := br.column(icol, beg, end)
+	if err != nil {
+		return nil, err
+	}
+	vs := make([]float64, len(words))
+	for i, w := range words {
+		switch br.forms[icol] {
+		case 'D':
+			vs[i] = math.Float64frombits(w)
+		default:
+			vs[i] = float64(int64(w))
+		}
+	}
+	return vs, nil
+}
+
+// column returns the raw values of the column icol for the iterations
+// [beg, end), reading only the chunks overlapping that range.
+func (br *BinaryReader) column(icol, beg, end int) ([]uint64, error) {
+	if beg < 0 || end < beg || end > br.Len() {
+		return nil, fmt.Errorf("snio: invalid iteration range [%d, %d) (records: %d)", beg, end, br.Len())
+	}
+	vs := make([]uint64, 0, end-beg)
+	var buf []uint64
+	for _, c := range br.index {
+		if c.first+c.nrows <= beg || c.first >= end {
+			continue
+		}
+		sizes, err := br.sizes(c)
+		if err != nil {
+			return nil, err
+		}
+		off := c.offset + int64(12+4*len(br.forms))
+		for _, n := range sizes[:icol] {
+			off += int64(n)
+		}
+		data := make([]byte, sizes[icol])
+		_, err = br.r.ReadAt(data, off)
+		if err != nil {
+			return nil, err
+		}
+		buf, err = decodeColumn(buf[:0], data, br.forms[icol], c.nrows)
+		if err != nil {
+			return nil, err
+		}
+		lo, hi := 0, c.nrows
+		if beg > c.first {
+			lo = beg - c.first
+		}
+		if end < c.first+c.nrows {
+			hi = end - c.first
+		}
+		vs = append(vs, buf[lo:hi]...)
+	}
+	return vs, nil
+}
+
+// sizes returns the sizes of the columns of the chunk c.
+func (br *BinaryReader) sizes(c binChunk) ([]int, error) {
+	hdr := make([]byte, 12+4*len(br.forms))
+	_, err := br.r.ReadAt(hdr, c.offset)
+	if err != nil {
+		return nil, err
+	}
+	if n := int(binary.LittleEndian.Uint32(hdr)); n != c.nrows {
+		return nil, fmt.Errorf("snio: invalid binary chunk at offset %d", c.offset)
+	}
+	sizes := make([]int, len(br.forms))
+	for i := range sizes {
+		sizes[i] = int(binary.LittleEndian.Uint32(hdr[12+4*i:]))
+	}
+	return sizes, nil
+}
+
+// BinaryFile is a binary snfusion file opened for random access.
+type BinaryFile struct {
+	*BinaryReader
+	f *os.File
+}
+
+// OpenBinary opens the named binary snfusion file and reads its metadata
+// and its index.
+func OpenBinary(fname string) (*BinaryFile, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r, err := NewBinaryReader(f, fi.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &BinaryFile{BinaryReader: r, f: f}, nil
+}
+
+// Close closes the file.
+func (f *BinaryFile) Close() error {
+	return f.f.Close()
+}
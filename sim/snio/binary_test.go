@@ -0,0 +1,142 @@
+package snio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/astrogo/snfusion/sim"
+)
+
+// binaryFile returns a binary file spanning several chunks, with the records
+// and the reaction flux of its run.
+func binaryFile(t *testing.T) ([]byte, []Record, sim.ReactionFlux) {
+	t.Helper()
+	e := newEngine()
+	e.NumIters = 2*binChunkSize + 100
+	var buf bytes.Buffer
+	recs, flux := runOutput(t, e, NewBinaryWriter(&buf))
+	if got, want := len(recs), e.NumIters+1; got != want {
+		t.Fatalf("invalid number of records: got=%d, want=%d", got, want)
+	}
+	return buf.Bytes(), recs, flux
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	src, recs, flux := binaryFile(t)
+	readAll(t, src, recs, flux)
+}
+
+func TestBinaryReader(t *testing.T) {
+	src, recs, flux := binaryFile(t)
+	br, err := NewBinaryReader(bytes.NewReader(src), int64(len(src)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := br.Len(), len(recs); got != want {
+		t.Fatalf("invalid number of records: got=%d, want=%d", got, want)
+	}
+	if got, want := len(br.index), 3; got != want {
+		t.Fatalf("invalid number of chunks: got=%d, want=%d", got, want)
+	}
+	if !reflect.DeepEqual(br.Flux(), flux) {
+		t.Fatalf("invalid reaction flux:\ngot= %v\nwant=%v", br.Flux(), flux)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		beg, end int
+	}{
+		{"empty", 10, 10},
+		{"first", 0, 1},
+		{"first-chunk", 0, binChunkSize},
+		{"chunk-boundary", binChunkSize - 6, binChunkSize + 4},
+		{"one-after-boundary", binChunkSize, binChunkSize + 1},
+		{"three-chunks", binChunkSize - 1, 2*binChunkSize + 1},
+		{"last-chunk", 2 * binChunkSize, len(recs)},
+		{"all", 0, len(recs)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := br.Records(tc.beg, tc.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkRecords(t, got, recs[tc.beg:tc.end])
+
+			// a population column and the floating point columns.
+			cols := map[string]func(rec Record) float64{
+				br.Meta.Columns[0].Name: func(rec Record) float64 { return float64(rec.Masses[0]) },
+				"T9":                    func(rec Record) float64 { return rec.T9 },
+				"Ye":                    func(rec Record) float64 { return rec.Ye },
+			}
+			for name, value := range cols {
+				vs, err := br.Column(name, tc.beg, tc.end)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(vs) != tc.end-tc.beg {
+					t.Fatalf("invalid length of column %s: got=%d, want=%d", name, len(vs), tc.end-tc.beg)
+				}
+				for i, v := range vs {
+					if want := value(recs[tc.beg+i]); v != want {
+						t.Fatalf("invalid value of column %s at iteration %d: got=%v, want=%v",
+							name, tc.beg+i, v, want)
+					}
+				}
+			}
+		})
+	}
+
+	for _, tc := range []struct {
+		name     string
+		beg, end int
+	}{
+		{"negative", -1, 10},
+		{"reversed", 10, 5},
+		{"past-end", 0, len(recs) + 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := br.Records(tc.beg, tc.end)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+
+	_, err = br.Column("no-such-column", 0, 1)
+	if err == nil {
+		t.Fatalf("expected an error for an unknown column")
+	}
+}
+
+func TestBinaryTruncated(t *testing.T) {
+	src, recs, _ := binaryFile(t)
+	index := int(binary.LittleEndian.Uint64(src[len(src)-binFooter:]))
+
+	for _, tc := range []struct {
+		name  string
+		size  int
+		nrecs int  // maximum number of records read sequentially
+		index bool // whether only the index is missing
+	}{
+		{name: "header", size: 20},
+		{name: "first-chunk", size: len(src) / 4, nrecs: binChunkSize - 1},
+		{name: "last-chunk", size: len(src) * 3 / 4, nrecs: len(recs) - 1},
+		{name: "flux", size: index - 10, nrecs: len(recs)},
+		{name: "index", size: index + 10, index: true},
+		{name: "footer", size: len(src) - 1, index: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewBinaryReader(bytes.NewReader(src[:tc.size]), int64(tc.size))
+			if err != ErrTruncated {
+				t.Fatalf("invalid error: got=%v, want=%v", err, ErrTruncated)
+			}
+			if tc.index {
+				// the index is not needed to read the file sequentially.
+				return
+			}
+			readTruncated(t, src[:tc.size], tc.nrecs)
+		})
+	}
+}
@@ -0,0 +1,526 @@
+package snio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/astrogo/snfusion/sim"
+)
+
+// FITS files written by FITSWriter hold:
+//   - a primary HDU without data, with the metadata of the run as keywords,
+//   - a RECORDS binary table, with an ITER column followed by one column per
+//     output column of the run (see sim.Metadata),
+//   - a FLUX binary table, with the integrated reaction flux of the run.
+//
+// The complete JSON encoded metadata is stored in the SNFMETA keyword,
+// using the CONTINUE long string convention.
+
+const (
+	fitsBlock  = 2880 // size of a FITS block, in bytes
+	fitsCard   = 80   // size of a FITS header card, in bytes
+	fitsMagic  = "SIMPLE  ="
+	fitsMaxStr = 66 // maximum length of a long string chunk, quotes and '&' excluded
+)
+
+// fluxColumns are the columns of the FLUX binary table.
+var fluxColumns = []struct {
+	name string
+	form byte
+}{
+	{"A1", 'J'}, {"Z1", 'J'}, {"A2", 'J'}, {"Z2", 'J'},
+	{"ATTEMPTS", 'K'}, {"FUSIONS", 'K'},
+}
+
+// FITSWriter writes the output of a simulation as a FITS file.
+// FITSWriter implements sim.Output.
+type FITSWriter struct {
+	w     *bufio.Writer
+	n     int64 // number of bytes written
+	meta  sim.Metadata
+	nrows int // number of records declared in the RECORDS table header
+	rows  int // number of records written
+	row   []byte
+	words []uint64
+}
+
+// NewFITSWriter returns a new FITSWriter writing to w.
+func NewFITSWriter(w io.Writer) *FITSWriter {
+	return &FITSWriter{w: bufio.NewWriter(w)}
+}
+
+// WriteHeader implements sim.Output.
+func (fw *FITSWriter) WriteHeader(meta sim.Metadata) error {
+	fw.meta = meta
+	fw.nrows = meta.Config.NumIters + 1
+
+	js, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	var hdr fitsHeader
+	hdr.logical("SIMPLE", true, "conforms to FITS standard")
+	hdr.int("BITPIX", 8, "")
+	hdr.int("NAXIS", 0, "no primary data")
+	hdr.logical("EXTEND", true, "")
+	hdr.str("ORIGIN", "snfusion", "")
+	if !meta.Created.IsZero() {
+		hdr.str("DATE", meta.Created.UTC().Format("2006-01-02T15:04:05"), "creation date (UTC)")
+	}
+	hdr.int("SNFFMT", int64(meta.Format), "snfusion output format version")
+	hdr.str("SNFVERS", meta.Version, "snfusion version")
+	hdr.int("NITERS", int64(meta.Config.NumIters), "number of iterations")
+	hdr.float("NCARBONS", meta.Config.NumCarbons, "carbon ratio (0-100)")
+	hdr.int("SEED", meta.Config.Seed, "seed of the MonteCarlo")
+	hdr.str("MODEL", meta.Config.Model, "fusion model")
+	hdr.float("T9", meta.Config.Conditions.T9, "initial temperature [GK]")
+	hdr.float("RHO", meta.Config.Conditions.Rho, "density [g/cm^3]")
+	hdr.str("LONGSTRN", "OGIP 1.0", "CONTINUE long string convention")
+	hdr.long("SNFMETA", string(js))
+	err = fw.write(hdr.bytes())
+	if err != nil {
+		return err
+	}
+
+	forms := columnForms(&meta.Config)
+	size := 8 * (len(forms) + 1)
+	hdr = fitsHeader{}
+	hdr.bintable("RECORDS", size, fw.nrows, len(forms)+1)
+	hdr.str("TTYPE1", "ITER", "")
+	hdr.str("TFORM1", "K", "")
+	for i, col := range meta.Columns {
+		j := strconv.Itoa(i + 2)
+		hdr.str("TTYPE"+j, col.Name, "")
+		hdr.str("TFORM"+j, string(forms[i]), "")
+		if col.Unit != "" {
+			hdr.str("TUNIT"+j, col.Unit, "")
+		}
+	}
+	fw.row = make([]byte, 0, size)
+	return fw.write(hdr.bytes())
+}
+
+// WriteRecord implements sim.Output.
+func (fw *FITSWriter) WriteRecord(rec sim.Record) error {
+	if fw.rows >= fw.nrows {
+		return fmt.Errorf("snio: too many records for FITS table (max: %d)", fw.nrows)
+	}
+	fw.words = columnValues(fw.words[:0], &fw.meta.Config, rec)
+	row := binary.BigEndian.AppendUint64(fw.row[:0], uint64(rec.Iter))
+	for _, v := range fw.words {
+		row = binary.BigEndian.AppendUint64(row, v)
+	}
+	fw.row = row
+	fw.rows++
+	return fw.write(row)
+}
+
+// WriteFlux implements sim.Output.
+// WriteFlux completes the RECORDS table and writes the FLUX table.
+func (fw *FITSWriter) WriteFlux(rf sim.ReactionFlux) error {
+	if fw.rows != fw.nrows {
+		return fmt.Errorf(
+			"snio: invalid number of records for FITS table (got=%d, want=%d)",
+			fw.rows, fw.nrows,
+		)
+	}
+	err := fw.pad()
+	if err != nil {
+		return err
+	}
+
+	size := 0
+	for _, col := range fluxColumns {
+		size += fitsSize(col.form)
+	}
+	var hdr fitsHeader
+	hdr.bintable("FLUX", size, len(rf), len(fluxColumns))
+	for i, col := range fluxColumns {
+		j := strconv.Itoa(i + 1)
+		hdr.str("TTYPE"+j, col.name, "")
+		hdr.str("TFORM"+j, string(col.form), "")
+	}
+	err = fw.write(hdr.bytes())
+	if err != nil {
+		return err
+	}
+
+	row := make([]byte, 0, size)
+	for _, f := range rf {
+		row = row[:0]
+		for _, v := range []int{f.Pair[0].A, f.Pair[0].Z, f.Pair[1].A, f.Pair[1].Z} {
+			row = binary.BigEndian.AppendUint32(row, uint32(v))
+		}
+		row = binary.BigEndian.AppendUint64(row, uint64(f.Attempts))
+		row = binary.BigEndian.AppendUint64(row, uint64(f.Fusions))
+		err = fw.write(row)
+		if err != nil {
+			return err
+		}
+	}
+	return fw.pad()
+}
+
+// Flush implements sim.Output.
+func (fw *FITSWriter) Flush() error {
+	return fw.w.Flush()
+}
+
+func (fw *FITSWriter) write(p []byte) error {
+	n, err := fw.w.Write(p)
+	fw.n += int64(n)
+	return err
+}
+
+// pad completes the current FITS data block with zeros.
+func (fw *FITSWriter) pad() error {
+	n := int(fw.n % fitsBlock)
+	if n == 0 {
+		return nil
+	}
+	return fw.write(make([]byte, fitsBlock-n))
+}
+
+// fitsSize returns the size in bytes of a binary table field of format form.
+func fitsSize(form byte) int {
+	switch form {
+	case 'J':
+		return 4
+	case 'K', 'D':
+		return 8
+	}
+	return 0
+}
+
+// fitsHeader is a FITS header under construction.
+type fitsHeader struct {
+	cards []string
+}
+
+func (h *fitsHeader) card(key, value, comment string) {
+	c := fmt.Sprintf("%-8s= %s", key, value)
+	if comment != "" {
+		c += " / " + comment
+	}
+	h.cards = append(h.cards, c)
+}
+
+func (h *fitsHeader) logical(key string, v bool, comment string) {
+	s := "F"
+	if v {
+		s = "T"
+	}
+	h.card(key, fmt.Sprintf("%20s", s), comment)
+}
+
+func (h *fitsHeader) int(key string, v int64, comment string) {
+	h.card(key, fmt.Sprintf("%20d", v), comment)
+}
+
+func (h *fitsHeader) float(key string, v float64, comment string) {
+	s := strconv.FormatFloat(v, 'G', -1, 64)
+	if !strings.ContainsAny(s, ".N") {
+		// FITS real values have a decimal point.
+		if i := strings.Index(s, "E"); i >= 0 {
+			s = s[:i] + ".0" + s[i:]
+		} else {
+			s += ".0"
+		}
+	}
+	h.card(key, fmt.Sprintf("%20s", s), comment)
+}
+
+func (h *fitsHeader) str(key, v, comment string) {
+	h.card(key, fmt.Sprintf("%-20s", quote(v)), comment)
+}
+
+// long adds a string value of any length, split over CONTINUE cards.
+func (h *fitsHeader) long(key, v string) {
+	var chunks []string
+	for len(v) > 0 {
+		n := 0
+		size := 0
+		for n < len(v) {
+			w := 1
+			if v[n] == '\'' {
+				w = 2
+			}
+			if size+w > fitsMaxStr {
+				break
+			}
+			size += w
+			n++
+		}
+		// trailing spaces of FITS strings are not significant.
+		for n > 1 && n < len(v) && v[n-1] == ' ' {
+			n--
+		}
+		chunks = append(chunks, v[:n])
+		v = v[n:]
+	}
+	if len(chunks) == 0 {
+		chunks = append(chunks, "")
+	}
+	for i, chunk := range chunks {
+		if i < len(chunks)-1 {
+			chunk += "&"
+		}
+		if i == 0 {
+			h.card(key, quote(chunk), "")
+			continue
+		}
+		h.cards = append(h.cards, "CONTINUE  "+quote(chunk))
+	}
+}
+
+// bintable adds the mandatory keywords of a binary table extension.
+func (h *fitsHeader) bintable(name string, width, nrows, nfields int) {
+	h.str("XTENSION", "BINTABLE", "binary table extension")
+	h.int("BITPIX", 8, "")
+	h.int("NAXIS", 2, "")
+	h.int("NAXIS1", int64(width), "width of table in bytes")
+	h.int("NAXIS2", int64(nrows), "number of rows")
+	h.int("PCOUNT", 0, "")
+	h.int("GCOUNT", 1, "")
+	h.int("TFIELDS", int64(nfields), "number of columns")
+	h.str("EXTNAME", name, "")
+}
+
+// bytes returns the header, terminated by an END card and padded to
+// a whole number of FITS blocks.
+func (h *fitsHeader) bytes() []byte {
+	var buf bytes.Buffer
+	for _, c := range append(h.cards, "END") {
+		if len(c) > fitsCard {
+			c = c[:fitsCard]
+		}
+		fmt.Fprintf(&buf, "%-80s", c)
+	}
+	if n := buf.Len() % fitsBlock; n != 0 {
+		buf.Write(bytes.Repeat([]byte(" "), fitsBlock-n))
+	}
+	return buf.Bytes()
+}
+
+// quote returns the FITS string literal of s, padded to at least 8 characters.
+func quote(s string) string {
+	return "'" + fmt.Sprintf("%-8s", strings.Replace(s, "'", "''", -1)) + "'"
+}
+
+// fitsReader reads the records of a FITS file written by a FITSWriter.
+type fitsReader struct {
+	r     *bufio.Reader
+	n     int64 // number of bytes read
+	forms []byte
+	nrows int
+	row   []byte
+}
+
+// readFITSHeader reads the primary HDU and the header of the RECORDS table
+// of the FITS file r.
+func readFITSHeader(r *bufio.Reader) (*fitsReader, sim.Metadata, error) {
+	fr := &fitsReader{r: r}
+	kw, err := fr.header()
+	if err != nil {
+		return nil, sim.Metadata{}, err
+	}
+	if kw["SIMPLE"] != "T" || kw["NAXIS"] != "0" {
+		return nil, sim.Metadata{}, fmt.Errorf("snio: invalid FITS primary header")
+	}
+	js, ok := kw["SNFMETA"]
+	if !ok {
+		return nil, sim.Metadata{}, ErrNoHeader
+	}
+	meta, err := sim.ParseMetadata([]byte(js))
+	if err != nil {
+		return nil, meta, fmt.Errorf("snio: invalid FITS metadata: %w", err)
+	}
+
+	kw, err = fr.table("RECORDS")
+	if err != nil {
+		return nil, meta, err
+	}
+	tfields, _ := strconv.Atoi(kw["TFIELDS"])
+	if tfields != len(meta.Columns)+1 {
+		return nil, meta, fmt.Errorf(
+			"snio: invalid number of columns in FITS table (got=%d, want=%d)",
+			tfields, len(meta.Columns)+1,
+		)
+	}
+	want := append([]byte{'K'}, columnForms(&meta.Config)...)
+	for i, form := range want {
+		if got := kw["TFORM"+strconv.Itoa(i+1)]; got != string(form) && got != "1"+string(form) {
+			return nil, meta, fmt.Errorf("snio: invalid format %q of FITS column %d", got, i+1)
+		}
+	}
+	fr.forms = want
+	fr.nrows, _ = strconv.Atoi(kw["NAXIS2"])
+	fr.row = make([]byte, 8*len(want))
+	return fr, meta, nil
+}
+
+// next reads the next row of the RECORDS table.
+func (fr *fitsReader) next(r *Reader) (Record, error) {
+	if r.iter >= fr.nrows {
+		err := fr.flux(r)
+		if err != nil {
+			return Record{}, err
+		}
+		return Record{}, io.EOF
+	}
+	err := fr.read(fr.row)
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return Record{}, ErrTruncated
+		}
+		return Record{}, err
+	}
+	word := func(i int) uint64 {
+		return binary.BigEndian.Uint64(fr.row[8*i:])
+	}
+	rec := r.record(
+		func(i int) int { return int(int64(word(i + 1))) },
+		func(i int) float64 { return math.Float64frombits(word(i + 1)) },
+	)
+	rec.Iter = int(int64(word(0)))
+	r.iter++
+	return rec, nil
+}
+
+// flux reads the FLUX table.
+func (fr *fitsReader) flux(r *Reader) error {
+	err := fr.skip()
+	if err != nil {
+		return err
+	}
+	kw, err := fr.table("FLUX")
+	if err == io.EOF {
+		return ErrTruncated
+	}
+	if err != nil {
+		return err
+	}
+	nrows, _ := strconv.Atoi(kw["NAXIS2"])
+	row := make([]byte, 32)
+	rf := make(sim.ReactionFlux, 0, nrows)
+	for i := 0; i < nrows; i++ {
+		err = fr.read(row)
+		if err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return ErrTruncated
+			}
+			return err
+		}
+		i32 := func(j int) int { return int(int32(binary.BigEndian.Uint32(row[4*j:]))) }
+		i64 := func(j int) int { return int(int64(binary.BigEndian.Uint64(row[16+8*j:]))) }
+		rf = append(rf, sim.Flux{
+			Pair:     sim.Pair{{A: i32(0), Z: i32(1)}, {A: i32(2), Z: i32(3)}},
+			Attempts: i64(0),
+			Fusions:  i64(1),
+		})
+	}
+	r.flux = rf
+	return nil
+}
+
+// table reads the header of the named binary table extension.
+func (fr *fitsReader) table(name string) (map[string]string, error) {
+	kw, err := fr.header()
+	if err != nil {
+		return nil, err
+	}
+	if kw["XTENSION"] != "BINTABLE" || kw["EXTNAME"] != name {
+		return nil, fmt.Errorf("snio: missing FITS %s table", name)
+	}
+	return kw, nil
+}
+
+// header reads a FITS header and returns the values of its keywords.
+// String values are unquoted; long strings are reassembled.
+func (fr *fitsReader) header() (map[string]string, error) {
+	kw := make(map[string]string)
+	block := make([]byte, fitsBlock)
+	long := "" // keyword of the long string being continued
+	for {
+		err := fr.read(block)
+		if err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = ErrTruncated
+			}
+			return nil, err
+		}
+		for i := 0; i < fitsBlock; i += fitsCard {
+			card := string(block[i : i+fitsCard])
+			key := strings.TrimSpace(card[:8])
+			switch {
+			case key == "END":
+				return kw, nil
+			case key == "CONTINUE" && long != "":
+				v := unquote(strings.TrimSpace(card[8:]))
+				kw[long] = strings.TrimSuffix(kw[long], "&") + v
+				if !strings.HasSuffix(v, "&") {
+					long = ""
+				}
+				continue
+			case card[8:10] != "= ":
+				long = ""
+				continue
+			}
+			v := strings.TrimSpace(card[10:])
+			if strings.HasPrefix(v, "'") {
+				v = unquote(v)
+			} else if j := strings.Index(v, "/"); j >= 0 {
+				v = strings.TrimSpace(v[:j])
+			}
+			kw[key] = v
+			long = ""
+			if strings.HasSuffix(v, "&") {
+				long = key
+			}
+		}
+	}
+}
+
+// skip skips to the end of the current FITS block.
+func (fr *fitsReader) skip() error {
+	n := int(fr.n % fitsBlock)
+	if n == 0 {
+		return nil
+	}
+	err := fr.read(make([]byte, fitsBlock-n))
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrTruncated
+	}
+	return err
+}
+
+func (fr *fitsReader) read(p []byte) error {
+	n, err := io.ReadFull(fr.r, p)
+	fr.n += int64(n)
+	return err
+}
+
+// unquote returns the value of the FITS string literal starting s.
+func unquote(s string) string {
+	var buf strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] == '\'' {
+			if i+1 < len(s) && s[i+1] == '\'' {
+				buf.WriteByte('\'')
+				i++
+				continue
+			}
+			break
+		}
+		buf.WriteByte(s[i])
+	}
+	return strings.TrimRight(buf.String(), " ")
+}
@@ -0,0 +1,60 @@
+package snio
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFITSRoundTrip(t *testing.T) {
+	e := newEngine()
+	e.NumIters = 500
+	var buf bytes.Buffer
+	recs, flux := runOutput(t, e, NewFITSWriter(&buf))
+	if got, want := len(recs), e.NumIters+1; got != want {
+		t.Fatalf("invalid number of records: got=%d, want=%d", got, want)
+	}
+	if n := buf.Len(); n%fitsBlock != 0 {
+		t.Fatalf("invalid FITS file size: %d", n)
+	}
+	readAll(t, buf.Bytes(), recs, flux)
+
+	meta, err := ReadHeader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := meta.Config, e.RunConfig(); got.NumIters != want.NumIters || got.Seed != want.Seed {
+		t.Errorf("invalid configuration: got=%+v, want=%+v", got, want)
+	}
+}
+
+func TestFITSTruncated(t *testing.T) {
+	e := newEngine()
+	e.NumIters = 500
+	var buf bytes.Buffer
+	recs, _ := runOutput(t, e, NewFITSWriter(&buf))
+	src := buf.Bytes()
+
+	// headers of the RECORDS and FLUX tables.
+	flux := bytes.LastIndex(src, []byte("XTENSION= 'BINTABLE'"))
+	records := bytes.Index(src, []byte("XTENSION= 'BINTABLE'"))
+	if records <= 0 || flux <= records {
+		t.Fatalf("missing FITS tables")
+	}
+
+	for _, tc := range []struct {
+		name  string
+		size  int
+		nrecs int
+	}{
+		{"primary-header", 100, 0},
+		{"records-header", records + 100, 0},
+		{"records", (records + flux) / 2, len(recs) - 1},
+		{"records-padding", flux - 1, len(recs)},
+		{"flux-header", flux + 100, len(recs)},
+		{"flux", flux + fitsBlock + 10, len(recs)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			readTruncated(t, src[:tc.size], tc.nrecs)
+		})
+	}
+}
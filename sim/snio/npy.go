@@ -0,0 +1,320 @@
+package snio
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/astrogo/snfusion/sim"
+)
+
+// NPYWriter writes the abundance matrix of a simulation as a NumPy .npy file.
+// The matrix holds one row per record and one column per nucleus of
+// the population, with the total mass number of that nucleus, as
+// little-endian 64-bit integers:
+//
+//	masses = numpy.load("output.npy")
+//
+// NPYWriter implements sim.Output.
+type NPYWriter struct {
+	w     *bufio.Writer
+	meta  sim.Metadata
+	nrows int
+	rows  int
+	row   []byte
+}
+
+// NewNPYWriter returns a new NPYWriter writing to w.
+func NewNPYWriter(w io.Writer) *NPYWriter {
+	return &NPYWriter{w: bufio.NewWriter(w)}
+}
+
+// WriteHeader implements sim.Output.
+func (nw *NPYWriter) WriteHeader(meta sim.Metadata) error {
+	nw.meta = meta
+	nw.nrows = meta.Config.NumIters + 1
+	_, err := nw.w.Write(npyHeader("<i8", nw.nrows, len(meta.Config.Population)))
+	return err
+}
+
+// WriteRecord implements sim.Output.
+func (nw *NPYWriter) WriteRecord(rec sim.Record) error {
+	if nw.rows >= nw.nrows {
+		return fmt.Errorf("snio: too many records for NumPy array (max: %d)", nw.nrows)
+	}
+	nw.row = massesRow(nw.row[:0], nw.meta.Config.Population, rec)
+	nw.rows++
+	_, err := nw.w.Write(nw.row)
+	return err
+}
+
+// WriteFlux implements sim.Output.
+// The reaction flux is not written to .npy files.
+func (nw *NPYWriter) WriteFlux(rf sim.ReactionFlux) error {
+	if nw.rows != nw.nrows {
+		return fmt.Errorf(
+			"snio: invalid number of records for NumPy array (got=%d, want=%d)",
+			nw.rows, nw.nrows,
+		)
+	}
+	return nil
+}
+
+// Flush implements sim.Output.
+func (nw *NPYWriter) Flush() error {
+	return nw.w.Flush()
+}
+
+// NPZWriter writes the output of a simulation as a NumPy .npz archive,
+// holding the following arrays:
+//   - masses: the abundance matrix (see NPYWriter),
+//   - iter: the iteration number of each record,
+//   - nuclides: the names of the nuclei of the population (e.g. "56Ni"),
+//   - acceptance: the acceptance statistics of each record, with the columns
+//     of sim.AcceptanceColumns (only if Acceptance is enabled),
+//   - t9: the temperature of each record (only if Heating is enabled),
+//   - ye: the electron fraction of each record (only if Capture is enabled),
+//   - flux: the reaction flux, one row of (A1, Z1, A2, Z2, attempts, fusions)
+//     per pair of nuclei,
+//   - metadata: the JSON encoded metadata of the run (see sim.Metadata).
+//
+// For example:
+//
+//	f = numpy.load("output.npz")
+//	meta = json.loads(f["metadata"].item())
+//
+// The masses array is written as records come in, the other per-record
+// arrays are kept in memory until the end of the run.
+//
+// NPZWriter implements sim.Output.
+type NPZWriter struct {
+	z     *zip.Writer
+	w     io.Writer // current entry of the archive
+	meta  sim.Metadata
+	nrows int
+	rows  int
+	row   []byte
+
+	iter []byte
+	acc  []byte
+	t9   []byte
+	ye   []byte
+}
+
+// NewNPZWriter returns a new NPZWriter writing to w.
+func NewNPZWriter(w io.Writer) *NPZWriter {
+	return &NPZWriter{z: zip.NewWriter(w)}
+}
+
+// WriteHeader implements sim.Output.
+func (zw *NPZWriter) WriteHeader(meta sim.Metadata) error {
+	zw.meta = meta
+	zw.nrows = meta.Config.NumIters + 1
+
+	var err error
+	zw.w, err = zw.create("masses")
+	if err != nil {
+		return err
+	}
+	_, err = zw.w.Write(npyHeader("<i8", zw.nrows, len(meta.Config.Population)))
+	return err
+}
+
+// WriteRecord implements sim.Output.
+func (zw *NPZWriter) WriteRecord(rec sim.Record) error {
+	if zw.rows >= zw.nrows {
+		return fmt.Errorf("snio: too many records for NumPy array (max: %d)", zw.nrows)
+	}
+	e := &zw.meta.Config
+	zw.row = massesRow(zw.row[:0], e.Population, rec)
+	zw.rows++
+	_, err := zw.w.Write(zw.row)
+	if err != nil {
+		return err
+	}
+
+	zw.iter = binary.LittleEndian.AppendUint64(zw.iter, uint64(rec.Iter))
+	if e.Acceptance {
+		for _, v := range []int{
+			rec.Acceptance.Attempts,
+			rec.Acceptance.Self,
+			rec.Acceptance.NoFusion,
+			rec.Acceptance.Unknown,
+			rec.Acceptance.Rejected,
+			rec.Size,
+		} {
+			zw.acc = binary.LittleEndian.AppendUint64(zw.acc, uint64(v))
+		}
+	}
+	if e.Heating.Enabled() {
+		zw.t9 = binary.LittleEndian.AppendUint64(zw.t9, math.Float64bits(rec.T9))
+	}
+	if e.Capture.Enabled() {
+		zw.ye = binary.LittleEndian.AppendUint64(zw.ye, math.Float64bits(rec.Ye))
+	}
+	return nil
+}
+
+// WriteFlux implements sim.Output.
+// WriteFlux writes all the remaining arrays and completes the archive.
+func (zw *NPZWriter) WriteFlux(rf sim.ReactionFlux) error {
+	if zw.rows != zw.nrows {
+		return fmt.Errorf(
+			"snio: invalid number of records for NumPy array (got=%d, want=%d)",
+			zw.rows, zw.nrows,
+		)
+	}
+	e := &zw.meta.Config
+
+	err := zw.array("iter", "<i8", zw.iter, zw.nrows)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(e.Population))
+	for i, n := range e.Population {
+		names[i] = n.Name()
+	}
+	descr, data := npyStrings(names)
+	err = zw.array("nuclides", descr, data, len(names))
+	if err != nil {
+		return err
+	}
+
+	if e.Acceptance {
+		err = zw.array("acceptance", "<i8", zw.acc, zw.nrows, len(sim.AcceptanceColumns))
+		if err != nil {
+			return err
+		}
+	}
+	if e.Heating.Enabled() {
+		err = zw.array("t9", "<f8", zw.t9, zw.nrows)
+		if err != nil {
+			return err
+		}
+	}
+	if e.Capture.Enabled() {
+		err = zw.array("ye", "<f8", zw.ye, zw.nrows)
+		if err != nil {
+			return err
+		}
+	}
+
+	flux := make([]byte, 0, 6*8*len(rf))
+	for _, f := range rf {
+		for _, v := range []int{f.Pair[0].A, f.Pair[0].Z, f.Pair[1].A, f.Pair[1].Z, f.Attempts, f.Fusions} {
+			flux = binary.LittleEndian.AppendUint64(flux, uint64(v))
+		}
+	}
+	err = zw.array("flux", "<i8", flux, len(rf), 6)
+	if err != nil {
+		return err
+	}
+
+	js, err := json.Marshal(zw.meta)
+	if err != nil {
+		return err
+	}
+	descr, data = npyStrings([]string{string(js)})
+	err = zw.array("metadata", descr, data)
+	if err != nil {
+		return err
+	}
+
+	return zw.z.Close()
+}
+
+// Flush implements sim.Output.
+func (zw *NPZWriter) Flush() error {
+	return zw.z.Flush()
+}
+
+// create starts the named array of the archive.
+func (zw *NPZWriter) create(name string) (io.Writer, error) {
+	return zw.z.CreateHeader(&zip.FileHeader{
+		Name:     name + ".npy",
+		Method:   zip.Deflate,
+		Modified: zw.meta.Created,
+	})
+}
+
+// array writes the named array of the archive.
+func (zw *NPZWriter) array(name, descr string, data []byte, shape ...int) error {
+	w, err := zw.create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(npyHeader(descr, shape...))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// massesRow appends to row the masses of the population of the record rec.
+func massesRow(row []byte, pop []sim.Nucleus, rec sim.Record) []byte {
+	for _, n := range pop {
+		row = binary.LittleEndian.AppendUint64(row, uint64(rec.Mass(n)))
+	}
+	return row
+}
+
+// npyHeader returns the header of a .npy file (format version 1.0) holding
+// a C-ordered array of the provided data type and shape.
+func npyHeader(descr string, shape ...int) []byte {
+	dims := make([]string, len(shape))
+	for i, n := range shape {
+		dims[i] = fmt.Sprintf("%d", n)
+	}
+	var tuple string
+	switch len(dims) {
+	case 1:
+		tuple = "(" + dims[0] + ",)"
+	default:
+		tuple = "(" + strings.Join(dims, ", ") + ")"
+	}
+	dict := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': %s, }", descr, tuple)
+
+	// the header is padded with spaces and terminated by a newline,
+	// so that the data starts on a 64 bytes boundary.
+	const prefix = 10 // magic string, version and header length
+	n := prefix + len(dict) + 1
+	pad := (64 - n%64) % 64
+	dict += strings.Repeat(" ", pad) + "\n"
+
+	var buf bytes.Buffer
+	buf.WriteString("\x93NUMPY\x01\x00")
+	binary.Write(&buf, binary.LittleEndian, uint16(len(dict)))
+	buf.WriteString(dict)
+	return buf.Bytes()
+}
+
+// npyStrings returns the NumPy data type and the data of an array of
+// unicode strings.
+func npyStrings(vs []string) (string, []byte) {
+	max := 1
+	for _, v := range vs {
+		if n := len([]rune(v)); n > max {
+			max = n
+		}
+	}
+	data := make([]byte, 0, 4*max*len(vs))
+	for _, v := range vs {
+		rs := []rune(v)
+		for i := 0; i < max; i++ {
+			var r rune
+			if i < len(rs) {
+				r = rs[i]
+			}
+			data = binary.LittleEndian.AppendUint32(data, uint32(r))
+		}
+	}
+	return fmt.Sprintf("<U%d", max), data
+}
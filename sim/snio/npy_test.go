@@ -0,0 +1,204 @@
+package snio
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/astrogo/snfusion/sim"
+)
+
+var npyDict = regexp.MustCompile(`^\{'descr': '([^']+)', 'fortran_order': False, 'shape': \(([^)]*)\), \} *\n$`)
+
+// readNPY decodes the .npy file data and returns the data type, the shape
+// and the data of its array.
+func readNPY(t *testing.T, data []byte) (string, string, []byte) {
+	t.Helper()
+	const prefix = 10
+	if len(data) < prefix || string(data[:8]) != "\x93NUMPY\x01\x00" {
+		t.Fatalf("invalid .npy magic: %q", data[:8])
+	}
+	n := int(binary.LittleEndian.Uint16(data[8:]))
+	if (prefix+n)%64 != 0 {
+		t.Fatalf("invalid .npy header size %d: data not aligned on 64 bytes", prefix+n)
+	}
+	m := npyDict.FindStringSubmatch(string(data[prefix : prefix+n]))
+	if m == nil {
+		t.Fatalf("invalid .npy header: %q", data[prefix:prefix+n])
+	}
+	return m[1], m[2], data[prefix+n:]
+}
+
+// int64s decodes little-endian 64-bit integers.
+func int64s(data []byte) []int {
+	vs := make([]int, len(data)/8)
+	for i := range vs {
+		vs[i] = int(int64(binary.LittleEndian.Uint64(data[8*i:])))
+	}
+	return vs
+}
+
+func TestNPYWriter(t *testing.T) {
+	e := newEngine()
+	var buf bytes.Buffer
+	recs, _ := runOutput(t, e, NewNPYWriter(&buf))
+
+	descr, shape, data := readNPY(t, buf.Bytes())
+	if descr != "<i8" {
+		t.Errorf("invalid data type: %q", descr)
+	}
+	npop := len(e.Population)
+	if want := fmt.Sprintf("%d, %d", len(recs), npop); shape != want {
+		t.Errorf("invalid shape: got=(%s), want=(%s)", shape, want)
+	}
+	if got, want := len(data), 8*len(recs)*npop; got != want {
+		t.Fatalf("invalid data size: got=%d, want=%d", got, want)
+	}
+	masses := int64s(data)
+	for i, rec := range recs {
+		if got := masses[i*npop : (i+1)*npop]; !reflect.DeepEqual(got, rec.Masses) {
+			t.Fatalf("record %d: invalid masses: got=%v, want=%v", i, got, rec.Masses)
+		}
+	}
+}
+
+func TestNPZWriter(t *testing.T) {
+	e := newEngine()
+	var buf bytes.Buffer
+	out := &tee{out: NewNPZWriter(&buf)}
+	err := e.RunOutput(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recs := out.recs
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrays := make(map[string][]byte)
+	var names []string
+	for _, f := range z.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", f.Name, err)
+		}
+		names = append(names, f.Name)
+		arrays[strings.TrimSuffix(f.Name, ".npy")] = data
+	}
+	sort.Strings(names)
+	want := []string{
+		"acceptance.npy", "flux.npy", "iter.npy", "masses.npy",
+		"metadata.npy", "nuclides.npy", "t9.npy", "ye.npy",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("invalid archive entries:\ngot= %v\nwant=%v", names, want)
+	}
+
+	// the masses array is the .npy output.
+	var npy bytes.Buffer
+	_, _ = runOutput(t, newEngine(), NewNPYWriter(&npy))
+	if !bytes.Equal(arrays["masses"], npy.Bytes()) {
+		t.Errorf("masses array differs from the .npy output")
+	}
+
+	_, shape, data := readNPY(t, arrays["iter"])
+	if shape != fmt.Sprintf("%d,", len(recs)) {
+		t.Errorf("invalid iter shape: (%s)", shape)
+	}
+	for i, v := range int64s(data) {
+		if v != recs[i].Iter {
+			t.Fatalf("invalid iteration of record %d: %d", i, v)
+		}
+	}
+
+	_, shape, data = readNPY(t, arrays["acceptance"])
+	if shape != fmt.Sprintf("%d, %d", len(recs), len(sim.AcceptanceColumns)) {
+		t.Errorf("invalid acceptance shape: (%s)", shape)
+	}
+	acc := int64s(data)
+	for i, rec := range recs {
+		if got := acc[i*len(rec.Acceptance) : (i+1)*len(rec.Acceptance)]; !reflect.DeepEqual(got, rec.Acceptance) {
+			t.Fatalf("record %d: invalid acceptance: got=%v, want=%v", i, got, rec.Acceptance)
+		}
+	}
+
+	for _, col := range []struct {
+		name  string
+		value func(rec Record) float64
+	}{
+		{"t9", func(rec Record) float64 { return rec.T9 }},
+		{"ye", func(rec Record) float64 { return rec.Ye }},
+	} {
+		descr, _, data := readNPY(t, arrays[col.name])
+		if descr != "<f8" {
+			t.Errorf("%s: invalid data type: %q", col.name, descr)
+		}
+		for i, rec := range recs {
+			v := math.Float64frombits(binary.LittleEndian.Uint64(data[8*i:]))
+			if v != col.value(rec) {
+				t.Fatalf("%s: invalid value of record %d: got=%v, want=%v", col.name, i, v, col.value(rec))
+			}
+		}
+	}
+
+	_, shape, data = readNPY(t, arrays["flux"])
+	if shape != fmt.Sprintf("%d, 6", len(out.flux)) {
+		t.Errorf("invalid flux shape: (%s)", shape)
+	}
+	flux := int64s(data)
+	for i, f := range out.flux {
+		want := []int{f.Pair[0].A, f.Pair[0].Z, f.Pair[1].A, f.Pair[1].Z, f.Attempts, f.Fusions}
+		if got := flux[6*i : 6*i+6]; !reflect.DeepEqual(got, want) {
+			t.Fatalf("invalid flux %d: got=%v, want=%v", i, got, want)
+		}
+	}
+
+	descr, shape, data := readNPY(t, arrays["nuclides"])
+	if descr != "<U4" || shape != fmt.Sprintf("%d,", len(e.Population)) {
+		t.Errorf("invalid nuclides array: %s (%s)", descr, shape)
+	}
+	for i, n := range e.Population {
+		if got := utf32(data[16*i : 16*(i+1)]); got != n.Name() {
+			t.Errorf("invalid nuclide %d: got=%q, want=%q", i, got, n.Name())
+		}
+	}
+
+	descr, shape, data = readNPY(t, arrays["metadata"])
+	if !strings.HasPrefix(descr, "<U") || shape != "" {
+		t.Fatalf("invalid metadata array: %s (%s)", descr, shape)
+	}
+	meta, err := sim.ParseMetadata([]byte(utf32(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(meta, out.meta) {
+		t.Fatalf("invalid metadata:\ngot= %+v\nwant=%+v", meta, out.meta)
+	}
+}
+
+// utf32 decodes a NumPy unicode string, padded with zeros.
+func utf32(data []byte) string {
+	var rs []rune
+	for i := 0; i+4 <= len(data); i += 4 {
+		r := rune(binary.LittleEndian.Uint32(data[i:]))
+		if r == 0 {
+			break
+		}
+		rs = append(rs, r)
+	}
+	return string(rs)
+}
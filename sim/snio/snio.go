@@ -0,0 +1,408 @@
+// Package snio reads and writes the output files of snfusion simulations.
+//
+// A snfusion CSV file starts with a metadata header line (see sim.HeaderCSV)
+// holding the JSON encoded metadata of the simulation (see sim.Metadata),
+// followed by one ';' separated record per iteration and by the reaction flux
+// trailer (see sim.HeaderFlux).
+// Files written by older versions of snfusion, whose header only holds the
+// configuration of the simulation engine, are read as well.
+//
+// Simulations may also be written as FITS files (see FITSWriter) or in
+// a compact binary format (see BinaryWriter and BinaryReader), read by
+// the same Reader, or exported as NumPy files (see NPYWriter and
+// NPZWriter.)
+//
+// Files of all formats may be gzip compressed: Reader and Open detect and
+// decompress them.
+package snio
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/astrogo/snfusion/sim"
+)
+
+const gzipMagic = "\x1f\x8b"
+
+var (
+	// ErrNoHeader is returned when a file has no snfusion metadata header.
+	ErrNoHeader = errors.New("snio: no snfusion header")
+
+	// ErrTruncated is returned when a file holds fewer records
+	// than the number of iterations of its simulation.
+	ErrTruncated = errors.New("snio: truncated file")
+)
+
+// FormatError describes a malformed line of a snfusion file.
+type FormatError struct {
+	Line int // line number, starting at 1
+	Msg  string
+}
+
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("snio: line %d: %s", e.Line, e.Msg)
+}
+
+// Record is the data of a single iteration.
+type Record struct {
+	Iter       int   // iteration number
+	Masses     []int // total mass number of each nucleus of the population
+	Acceptance []int // acceptance statistics (see sim.AcceptanceColumns), if any
+	T9         float64
+	Ye         float64
+}
+
+// Reader reads the records of a snfusion file.
+type Reader struct {
+	Meta   sim.Metadata // metadata of the file
+	Engine sim.Engine   // configuration of the simulation (same as Meta.Config.Engine())
+
+	s     *bufio.Scanner
+	dec   decoder // decoder of FITS and binary files
+	next  []byte  // line read ahead of the current one, if any
+	line  int
+	iter  int
+	ncols int
+	flux  sim.ReactionFlux
+	eof   bool
+	err   error // error returned once the end of a FITS or binary file is reached
+}
+
+// ReadHeader reads the metadata header of the snfusion file r.
+func ReadHeader(r io.Reader) (sim.Metadata, error) {
+	rr, err := NewReader(r)
+	if err != nil {
+		return sim.Metadata{}, err
+	}
+	return rr.Meta, nil
+}
+
+// NewReader returns a reader of the snfusion file r,
+// after having read its metadata header.
+// NewReader detects whether r is a CSV, a FITS (see FITSWriter) or
+// a binary (see BinaryWriter) file, and transparently decompresses
+// gzip compressed files.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(len(gzipMagic)); string(magic) == gzipMagic {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		br = bufio.NewReader(zr)
+	}
+
+	magic, _ := br.Peek(len(fitsMagic))
+	switch {
+	case bytes.HasPrefix(magic, []byte(fitsMagic)):
+		fr, meta, err := readFITSHeader(br)
+		if err != nil {
+			return nil, err
+		}
+		return &Reader{Meta: meta, Engine: meta.Config.Engine(), dec: fr}, nil
+	case bytes.HasPrefix(magic, []byte(binMagic)):
+		meta, err := readBinaryHeader(br)
+		if err != nil {
+			return nil, err
+		}
+		forms := columnForms(&meta.Config)
+		dec := &binReader{r: br, forms: forms, cols: make([][]uint64, len(forms))}
+		return &Reader{Meta: meta, Engine: meta.Config.Engine(), dec: dec}, nil
+	}
+
+	rr := &Reader{s: bufio.NewScanner(br)}
+	rr.s.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for rr.s.Scan() {
+		rr.line++
+		data := rr.s.Bytes()
+		if !bytes.HasPrefix(data, []byte("#")) {
+			return nil, ErrNoHeader
+		}
+		if !bytes.HasPrefix(data, sim.HeaderCSV) {
+			continue
+		}
+		meta, err := sim.ParseMetadata(data[len(sim.HeaderCSV):])
+		if err != nil {
+			return nil, &FormatError{Line: rr.line, Msg: fmt.Sprintf("invalid metadata: %v", err)}
+		}
+		rr.Meta = meta
+		rr.Engine = meta.Config.Engine()
+		rr.ncols = len(meta.Columns)
+		return rr, nil
+	}
+	err := rr.s.Err()
+	if err != nil {
+		return nil, err
+	}
+	return nil, ErrNoHeader
+}
+
+// Read returns the next record of the file.
+// Read returns io.EOF after the last record, or ErrTruncated when the file
+// ends before the last iteration of the simulation.
+func (r *Reader) Read() (Record, error) {
+	if r.dec != nil {
+		if r.eof {
+			return Record{}, r.err
+		}
+		rec, err := r.dec.next(r)
+		if err == io.EOF && r.iter < r.Engine.NumIters+1 {
+			err = ErrTruncated
+		}
+		if err != nil {
+			r.eof = true
+			r.err = err
+		}
+		return rec, err
+	}
+
+	for !r.eof && r.scan() {
+		r.line++
+		data := r.data()
+		if bytes.HasPrefix(data, sim.HeaderFlux) {
+			err := json.Unmarshal(data[len(sim.HeaderFlux):], &r.flux)
+			if err != nil {
+				return Record{}, &FormatError{Line: r.line, Msg: fmt.Sprintf("invalid reaction flux: %v", err)}
+			}
+			continue
+		}
+		if len(data) == 0 || data[0] == '#' {
+			continue
+		}
+		rec, err := r.parse(data)
+		if err != nil && !r.peek() && r.s.Err() == nil {
+			// a malformed last line is a partially written record.
+			r.eof = true
+			return Record{}, ErrTruncated
+		}
+		return rec, err
+	}
+	err := r.s.Err()
+	if err != nil {
+		return Record{}, err
+	}
+	r.eof = true
+	if r.iter < r.Engine.NumIters+1 {
+		return Record{}, ErrTruncated
+	}
+	return Record{}, io.EOF
+}
+
+// scan advances to the next line of a CSV file, the line read ahead
+// by peek if any.
+func (r *Reader) scan() bool {
+	if r.next != nil {
+		return true
+	}
+	return r.s.Scan()
+}
+
+// data returns the current line of a CSV file, consuming the line
+// read ahead by peek if any.
+func (r *Reader) data() []byte {
+	if r.next != nil {
+		data := r.next
+		r.next = nil
+		return data
+	}
+	return r.s.Bytes()
+}
+
+// peek reads ahead the next line of a CSV file, without consuming it,
+// and returns whether there is one.
+func (r *Reader) peek() bool {
+	if r.next != nil {
+		return true
+	}
+	if !r.s.Scan() {
+		return false
+	}
+	r.next = append([]byte{}, r.s.Bytes()...)
+	return true
+}
+
+// ReadAll returns all the remaining records of the file.
+func (r *Reader) ReadAll() ([]Record, error) {
+	var recs []Record
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return recs, nil
+		}
+		if err != nil {
+			return recs, err
+		}
+		recs = append(recs, rec)
+	}
+}
+
+// Flux returns the reaction flux trailer of the file, available once
+// all the records have been read.
+func (r *Reader) Flux() (sim.ReactionFlux, error) {
+	if r.flux == nil {
+		return nil, fmt.Errorf("snio: no reaction flux trailer")
+	}
+	return r.flux, nil
+}
+
+func (r *Reader) parse(data []byte) (Record, error) {
+	toks := bytes.Split(data, []byte(";"))
+	if len(toks) != r.ncols {
+		return Record{}, &FormatError{
+			Line: r.line,
+			Msg:  fmt.Sprintf("invalid number of columns (got=%d, want=%d)", len(toks), r.ncols),
+		}
+	}
+
+	var err error
+	atoi := func(i int) int {
+		if err != nil {
+			return 0
+		}
+		var v int
+		v, err = strconv.Atoi(string(toks[i]))
+		if err != nil {
+			err = &FormatError{Line: r.line, Msg: fmt.Sprintf("invalid column %d: %v", i, err)}
+		}
+		return v
+	}
+	atof := func(i int) float64 {
+		if err != nil {
+			return 0
+		}
+		var v float64
+		v, err = strconv.ParseFloat(string(toks[i]), 64)
+		if err != nil {
+			err = &FormatError{Line: r.line, Msg: fmt.Sprintf("invalid column %d: %v", i, err)}
+		}
+		return v
+	}
+
+	rec := r.record(atoi, atof)
+	if err != nil {
+		return Record{}, err
+	}
+
+	r.iter++
+	return rec, nil
+}
+
+// record returns the record made of the values of the output columns of
+// the simulation, as given by atoi and atof from the column index.
+func (r *Reader) record(atoi func(i int) int, atof func(i int) float64) Record {
+	rec := Record{
+		Iter:   r.iter,
+		Masses: make([]int, len(r.Engine.Population)),
+	}
+	col := 0
+	for i := range rec.Masses {
+		rec.Masses[i] = atoi(col)
+		col++
+	}
+	if r.Engine.Acceptance {
+		rec.Acceptance = make([]int, len(sim.AcceptanceColumns))
+		for i := range rec.Acceptance {
+			rec.Acceptance[i] = atoi(col)
+			col++
+		}
+	}
+	if r.Engine.Heating.Enabled() {
+		rec.T9 = atof(col)
+		col++
+	}
+	if r.Engine.Capture.Enabled() {
+		rec.Ye = atof(col)
+		col++
+	}
+	return rec
+}
+
+// decoder decodes the records of a FITS or binary file.
+type decoder interface {
+	// next returns the next record of the file, or io.EOF after
+	// the last one.
+	next(r *Reader) (Record, error)
+}
+
+// columnForms returns the binary formats of the output columns of
+// the simulation e, as FITS binary table codes: 'K' for 64-bit integers and
+// 'D' for 64-bit floating point numbers.
+func columnForms(e *sim.RunConfig) []byte {
+	var forms []byte
+	for range e.Population {
+		forms = append(forms, 'K')
+	}
+	if e.Acceptance {
+		for range sim.AcceptanceColumns {
+			forms = append(forms, 'K')
+		}
+	}
+	if e.Heating.Enabled() {
+		forms = append(forms, 'D')
+	}
+	if e.Capture.Enabled() {
+		forms = append(forms, 'D')
+	}
+	return forms
+}
+
+// columnValues appends to dst the values of the output columns of
+// the simulation e for the record rec, as 64-bit words: integers in two's
+// complement and floating point numbers in IEEE 754 format.
+func columnValues(dst []uint64, e *sim.RunConfig, rec sim.Record) []uint64 {
+	for _, n := range e.Population {
+		dst = append(dst, uint64(rec.Mass(n)))
+	}
+	if e.Acceptance {
+		dst = append(dst,
+			uint64(rec.Acceptance.Attempts),
+			uint64(rec.Acceptance.Self),
+			uint64(rec.Acceptance.NoFusion),
+			uint64(rec.Acceptance.Unknown),
+			uint64(rec.Acceptance.Rejected),
+			uint64(rec.Size),
+		)
+	}
+	if e.Heating.Enabled() {
+		dst = append(dst, math.Float64bits(rec.T9))
+	}
+	if e.Capture.Enabled() {
+		dst = append(dst, math.Float64bits(rec.Ye))
+	}
+	return dst
+}
+
+// File is a snfusion file opened for reading.
+type File struct {
+	*Reader
+	f *os.File
+}
+
+// Open opens the named snfusion file and reads its metadata header.
+func Open(fname string) (*File, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &File{Reader: r, f: f}, nil
+}
+
+// Close closes the file.
+func (f *File) Close() error {
+	return f.f.Close()
+}
@@ -0,0 +1,378 @@
+package snio
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/astrogo/snfusion/sim"
+)
+
+// newEngine returns a small simulation engine, with all the optional
+// output columns enabled.
+func newEngine() *sim.Engine {
+	e := &sim.Engine{
+		NumIters:   20,
+		NumCarbons: 60,
+		Seed:       1234,
+		Acceptance: true,
+		Conditions: sim.Conditions{T9: 3, Rho: 1e9},
+		Heating:    sim.Heating{HeatCapacity: 1000},
+		Capture:    sim.Capture{Rate: 1},
+	}
+	e.SetLogger(log.New(ioutil.Discard, "", 0))
+	return e
+}
+
+// csvFile returns the lines of the CSV output of a small simulation.
+func csvFile(t *testing.T) []string {
+	t.Helper()
+	var buf bytes.Buffer
+	err := newEngine().Run(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.SplitAfter(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+// tee is a sim.Output forwarding the output of a run to out, and keeping
+// the records and the reaction flux it was given.
+type tee struct {
+	out  sim.Output
+	meta sim.Metadata
+	recs []Record
+	flux sim.ReactionFlux
+}
+
+func (o *tee) WriteHeader(meta sim.Metadata) error {
+	o.meta = meta
+	return o.out.WriteHeader(meta)
+}
+
+func (o *tee) WriteRecord(rec sim.Record) error {
+	e := &o.meta.Config
+	r := Record{Iter: rec.Iter}
+	for _, n := range e.Population {
+		r.Masses = append(r.Masses, rec.Mass(n))
+	}
+	if e.Acceptance {
+		r.Acceptance = []int{
+			rec.Acceptance.Attempts,
+			rec.Acceptance.Self,
+			rec.Acceptance.NoFusion,
+			rec.Acceptance.Unknown,
+			rec.Acceptance.Rejected,
+			rec.Size,
+		}
+	}
+	if e.Heating.Enabled() {
+		r.T9 = rec.T9
+	}
+	if e.Capture.Enabled() {
+		r.Ye = rec.Ye
+	}
+	o.recs = append(o.recs, r)
+	return o.out.WriteRecord(rec)
+}
+
+func (o *tee) WriteFlux(rf sim.ReactionFlux) error {
+	o.flux = rf
+	return o.out.WriteFlux(rf)
+}
+
+func (o *tee) Flush() error {
+	return o.out.Flush()
+}
+
+// runOutput runs the simulation e with the output out and returns
+// the records and the reaction flux written to out.
+func runOutput(t *testing.T, e *sim.Engine, out sim.Output) ([]Record, sim.ReactionFlux) {
+	t.Helper()
+	o := &tee{out: out}
+	err := e.RunOutput(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o.recs, o.flux
+}
+
+// readAll reads sequentially all the records of the file src and
+// checks them against the records and the reaction flux of the run.
+func readAll(t *testing.T, src []byte, recs []Record, flux sim.ReactionFlux) {
+	t.Helper()
+	r, err := NewReader(bytes.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, got, recs)
+	rf, err := r.Flux()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rf, flux) {
+		t.Fatalf("invalid reaction flux:\ngot= %v\nwant=%v", rf, flux)
+	}
+}
+
+// checkRecords checks the records got against the records want.
+func checkRecords(t *testing.T, got, want []Record) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("invalid number of records: got=%d, want=%d", len(got), len(want))
+	}
+	for i := range got {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Fatalf("invalid record %d:\ngot= %+v\nwant=%+v", i, got[i], want[i])
+		}
+	}
+}
+
+// readTruncated reads sequentially the truncated file src and checks that
+// ErrTruncated is returned after at most nrecs records.
+func readTruncated(t *testing.T, src []byte, nrecs int) {
+	t.Helper()
+	r, err := NewReader(bytes.NewReader(src))
+	if err != nil {
+		if err != ErrTruncated {
+			t.Fatalf("invalid error: got=%v, want=%v", err, ErrTruncated)
+		}
+		return
+	}
+	n := 0
+	for {
+		_, err = r.Read()
+		if err != nil {
+			break
+		}
+		n++
+	}
+	if err != ErrTruncated {
+		t.Fatalf("invalid error: got=%v, want=%v", err, ErrTruncated)
+	}
+	if n > nrecs {
+		t.Fatalf("too many records: got=%d, want<=%d", n, nrecs)
+	}
+	if _, err = r.Read(); err != ErrTruncated {
+		t.Fatalf("invalid error after truncation: got=%v, want=%v", err, ErrTruncated)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	lines := csvFile(t)
+	const (
+		hdr   = 2 // metadata and column names lines
+		first = hdr
+		nrecs = 21
+	)
+	if got, want := len(lines), hdr+nrecs+1; got != want {
+		t.Fatalf("invalid number of lines: got=%d, want=%d", got, want)
+	}
+	join := func(lines ...[]string) string {
+		var o []string
+		for _, l := range lines {
+			o = append(o, l...)
+		}
+		return strings.Join(o, "")
+	}
+	malformed := []string{"1;2;x\n"}
+
+	for _, tc := range []struct {
+		name  string
+		src   string
+		err   error
+		nrecs int
+		line  int // line of the FormatError, if any
+	}{
+		{
+			name: "valid",
+			src:  join(lines),
+			err:  io.EOF, nrecs: nrecs,
+		},
+		{
+			name: "empty",
+			src:  "",
+			err:  ErrNoHeader,
+		},
+		{
+			name: "no-header",
+			src:  join(lines[hdr:]),
+			err:  ErrNoHeader,
+		},
+		{
+			name: "comments-only",
+			src:  "# a comment\n# another one\n",
+			err:  ErrNoHeader,
+		},
+		{
+			name: "truncated",
+			src:  join(lines[:first+10]),
+			err:  ErrTruncated, nrecs: 10,
+		},
+		{
+			name: "truncated-record",
+			src:  join(lines[:first+10]) + lines[first+10][:5],
+			err:  ErrTruncated, nrecs: 10,
+		},
+		{
+			name:  "malformed",
+			src:   join(lines[:first+5], malformed, lines[first+5:]),
+			nrecs: 5,
+			line:  first + 6,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(tc.src))
+			if tc.err == ErrNoHeader {
+				if err != ErrNoHeader {
+					t.Fatalf("invalid error: got=%v, want=%v", err, ErrNoHeader)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			n := 0
+			for {
+				_, err = r.Read()
+				if err != nil {
+					break
+				}
+				n++
+			}
+			if n != tc.nrecs {
+				t.Errorf("invalid number of records: got=%d, want=%d", n, tc.nrecs)
+			}
+
+			if tc.line == 0 {
+				if err != tc.err {
+					t.Fatalf("invalid error: got=%v, want=%v", err, tc.err)
+				}
+				return
+			}
+
+			var ferr *FormatError
+			if !errors.As(err, &ferr) {
+				t.Fatalf("invalid error: got=%v (%T), want a FormatError", err, err)
+			}
+			if ferr.Line != tc.line {
+				t.Errorf("invalid error line: got=%d, want=%d", ferr.Line, tc.line)
+			}
+
+			// the records following the malformed line are still available,
+			// with their line numbers.
+			for {
+				_, err = r.Read()
+				if err != nil {
+					break
+				}
+				n++
+			}
+			if err != io.EOF {
+				t.Fatalf("invalid error after malformed line: got=%v, want=%v", err, io.EOF)
+			}
+			if n != nrecs {
+				t.Errorf("invalid number of records: got=%d, want=%d", n, nrecs)
+			}
+			if want := first + nrecs + 2; r.line != want {
+				t.Errorf("invalid number of lines: got=%d, want=%d", r.line, want)
+			}
+		})
+	}
+}
+
+func TestReadHeader(t *testing.T) {
+	lines := csvFile(t)
+	meta, err := ReadHeader(strings.NewReader(strings.Join(lines, "")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Format != sim.FormatVersion {
+		t.Errorf("invalid format: got=%d, want=%d", meta.Format, sim.FormatVersion)
+	}
+	if got, want := len(meta.Columns), len(sim.Population)+len(sim.AcceptanceColumns)+2; got != want {
+		t.Errorf("invalid number of columns: got=%d, want=%d", got, want)
+	}
+	if meta.Config.NumIters != 20 || meta.Config.Seed != 1234 {
+		t.Errorf("invalid configuration: %+v", meta.Config)
+	}
+}
+
+func TestReadGzip(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		out  func(w io.Writer) sim.Output
+	}{
+		{"csv", func(w io.Writer) sim.Output { return sim.NewCSVWriter(w) }},
+		{"fits", func(w io.Writer) sim.Output { return NewFITSWriter(w) }},
+		{"binary", func(w io.Writer) sim.Output { return NewBinaryWriter(w) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var raw bytes.Buffer
+			runOutput(t, newEngine(), tc.out(&raw))
+
+			var gz bytes.Buffer
+			zw := gzip.NewWriter(&gz)
+			_, err := zw.Write(raw.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = zw.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			read := func(src []byte) (*Reader, []Record) {
+				r, err := NewReader(bytes.NewReader(src))
+				if err != nil {
+					t.Fatal(err)
+				}
+				recs, err := r.ReadAll()
+				if err != nil {
+					t.Fatal(err)
+				}
+				return r, recs
+			}
+			rr, want := read(raw.Bytes())
+			zr, got := read(gz.Bytes())
+			if len(want) != newEngine().NumIters+1 {
+				t.Fatalf("invalid number of records: %d", len(want))
+			}
+			checkRecords(t, got, want)
+			if !reflect.DeepEqual(zr.Meta, rr.Meta) {
+				t.Errorf("invalid metadata:\ngot= %+v\nwant=%+v", zr.Meta, rr.Meta)
+			}
+			if !reflect.DeepEqual(zr.flux, rr.flux) {
+				t.Errorf("invalid reaction flux:\ngot= %v\nwant=%v", zr.flux, rr.flux)
+			}
+
+			// a truncated compressed file is reported as such.
+			src := gz.Bytes()[:gz.Len()/2]
+			r, err := NewReader(bytes.NewReader(src))
+			if err == nil {
+				_, err = r.ReadAll()
+			}
+			if err == nil {
+				t.Errorf("expected an error for a truncated compressed file")
+			}
+
+			if tc.name != "binary" {
+				return
+			}
+			_, err = NewBinaryReader(bytes.NewReader(gz.Bytes()), int64(gz.Len()))
+			if err == nil {
+				t.Fatalf("expected an error for random access to a compressed binary file")
+			}
+		})
+	}
+}
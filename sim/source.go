@@ -0,0 +1,163 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+)
+
+// Abundance is the relative abundance, or weight, of a nucleus species.
+type Abundance struct {
+	Nucleus Nucleus
+	Weight  float64
+}
+
+// Source is a scheduled injection of nuclei into the population of
+// an Engine, modelling accretion.
+type Source struct {
+	Every       int         // injection period, in iterations
+	N           int         // number of nuclei injected per period
+	Composition []Abundance // composition of the injected nuclei (default: initial C/O composition)
+}
+
+// Sink is a scheduled removal of nuclei from the population of
+// an Engine, modelling mass-loss.
+// Removed nuclei are drawn with a probability proportional to the weight
+// of their species: species with a zero weight are never removed.
+type Sink struct {
+	Every    int         // removal period, in iterations
+	Fraction float64     // fraction of the population removed per period
+	Bias     []Abundance // relative removal weights of species (default: 1)
+}
+
+// validateTerms checks the source and sink terms of the simulation.
+func (e *Engine) validateTerms() error {
+	for _, src := range e.Sources {
+		for _, ab := range src.Composition {
+			if ab.Weight < 0 {
+				return fmt.Errorf("sim: negative source weight for %v", ab.Nucleus)
+			}
+		}
+	}
+	for _, sink := range e.Sinks {
+		if sink.Fraction < 0 || sink.Fraction > 1 {
+			return fmt.Errorf("sim: invalid sink fraction %g (want: 0-1)", sink.Fraction)
+		}
+		for _, ab := range sink.Bias {
+			if ab.Weight < 0 {
+				return fmt.Errorf("sim: negative sink weight for %v", ab.Nucleus)
+			}
+		}
+	}
+	return nil
+}
+
+// applyTerms applies the source and sink terms scheduled at
+// the current iteration.
+func (e *Engine) applyTerms() error {
+	for _, src := range e.Sources {
+		if src.Every <= 0 || e.iter%src.Every != 0 {
+			continue
+		}
+		err := e.inject(src)
+		if err != nil {
+			return err
+		}
+	}
+	for _, sink := range e.Sinks {
+		if sink.Every <= 0 || e.iter%sink.Every != 0 {
+			continue
+		}
+		e.eject(sink)
+	}
+	return nil
+}
+
+// inject adds nuclei to the population according to the source src.
+func (e *Engine) inject(src Source) error {
+	comp := src.Composition
+	if len(comp) == 0 {
+		comp = []Abundance{
+			{Nucleus: nC, Weight: e.NumCarbons},
+			{Nucleus: nO, Weight: 100 - e.NumCarbons},
+		}
+	}
+	total := 0.0
+	for _, ab := range comp {
+		total += ab.Weight
+	}
+	if total <= 0 {
+		return fmt.Errorf("sim: source with empty composition")
+	}
+
+	for k := 0; k < src.N; k++ {
+		u := e.rng.Float64() * total
+		n := comp[len(comp)-1].Nucleus
+		for _, ab := range comp {
+			if u < ab.Weight {
+				n = ab.Nucleus
+				break
+			}
+			u -= ab.Weight
+		}
+		e.nuclei = append(e.nuclei, n)
+		e.counts[n]++
+		e.totA += n.A
+		e.totZ += n.Z
+		e.touch(n)
+		e.addInjected(n)
+	}
+	return nil
+}
+
+// eject removes nuclei from the population according to the sink.
+func (e *Engine) eject(sink Sink) {
+	bias := make(map[Nucleus]float64, len(sink.Bias))
+	for _, ab := range sink.Bias {
+		bias[ab.Nucleus] = ab.Weight
+	}
+	weight := func(n Nucleus) float64 {
+		w, ok := bias[n]
+		if !ok {
+			return 1
+		}
+		return w
+	}
+
+	// stochastic rounding of the number of nuclei to remove.
+	n := int(math.Floor(sink.Fraction*float64(len(e.nuclei)) + e.rng.Float64()))
+	for k := 0; k < n; k++ {
+		species := e.species()
+		total := 0.0
+		for _, nuc := range species {
+			total += weight(nuc) * float64(e.counts[nuc])
+		}
+		if total <= 0 {
+			// no nucleus left with a positive weight.
+			return
+		}
+		u := e.rng.Float64() * total
+		nuc := species[len(species)-1]
+		for _, sp := range species {
+			w := weight(sp) * float64(e.counts[sp])
+			if u < w {
+				nuc = sp
+				break
+			}
+			u -= w
+		}
+		if weight(nuc) <= 0 {
+			// rounding errors of the cumulative weights.
+			for _, sp := range species {
+				if weight(sp) > 0 {
+					nuc = sp
+				}
+			}
+		}
+		i := e.pick(nuc, -1)
+		e.decr(nuc)
+		e.totA -= nuc.A
+		e.totZ -= nuc.Z
+		e.touch(nuc)
+		e.delete(i)
+	}
+}
@@ -0,0 +1,85 @@
+package sim
+
+import (
+	"testing"
+)
+
+func TestSinkValidation(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		sink Sink
+		err  string
+	}{
+		{"fraction-above-1", Sink{Every: 1, Fraction: 1.5}, "sim: invalid sink fraction 1.5 (want: 0-1)"},
+		{"negative-fraction", Sink{Every: 1, Fraction: -0.1}, "sim: invalid sink fraction -0.1 (want: 0-1)"},
+		{
+			"negative-bias",
+			Sink{Every: 1, Fraction: 0.1, Bias: []Abundance{{Nucleus: nC, Weight: -1}}},
+			"sim: negative sink weight for Nucleus{A: 12, Z: 6}",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := Engine{NumIters: 10, NumCarbons: 60, Sinks: []Sink{tc.sink}}
+			err := e.Start()
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if got := err.Error(); got != tc.err {
+				t.Fatalf("invalid error:\ngot= %v\nwant=%v", got, tc.err)
+			}
+		})
+	}
+}
+
+func TestSinkBias(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		bias    []Abundance
+		removed map[Nucleus]bool // species that may be removed
+	}{
+		{
+			name:    "zero-weights",
+			bias:    []Abundance{{Nucleus: nC}, {Nucleus: nO}},
+			removed: map[Nucleus]bool{},
+		},
+		{
+			name:    "oxygen-only",
+			bias:    []Abundance{{Nucleus: nC}, {Nucleus: nO, Weight: 2}},
+			removed: map[Nucleus]bool{nO: true},
+		},
+		{
+			name:    "default",
+			removed: map[Nucleus]bool{nC: true, nO: true},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := Engine{
+				NumIters:   1,
+				NumCarbons: 60,
+				Model:      "none",
+				Sinks:      []Sink{{Every: 1, Fraction: 0.2, Bias: tc.bias}},
+			}
+			e.SetModel(Standard{Chart: Chart{MaxA: 1}})
+			err := e.Start()
+			if err != nil {
+				t.Fatal(err)
+			}
+			before := e.Counts()
+			if !e.Next() {
+				t.Fatal(e.Err())
+			}
+			after := e.Counts()
+			total := 0
+			for n, c := range before {
+				removed := c - after[n]
+				total += removed
+				if removed > 0 && !tc.removed[n] {
+					t.Errorf("%v: %d nuclei removed with a zero weight", n, removed)
+				}
+			}
+			if len(tc.removed) > 0 && total < 1900 {
+				t.Errorf("invalid number of removed nuclei: %d", total)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package sim
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+)
+
+// Record is the state of the population of a simulation at the end of
+// an iteration.
+type Record struct {
+	Iter       int             // iteration number
+	Counts     map[Nucleus]int // number of nuclei of each species
+	T9         float64         // temperature, in GK
+	Ye         float64         // electron fraction
+	Size       int             // number of nuclei in the population
+	Acceptance AcceptanceStats // cumulative acceptance statistics
+}
+
+// Mass returns the total mass number of the nuclei of species n.
+func (r Record) Mass(n Nucleus) int {
+	return r.Counts[n] * n.A
+}
+
+// Start initializes the simulation so it can be driven step by step with
+// Next, without writing any output.
+// Messages of the run are discarded unless a logger was set with SetLogger.
+//
+//	err := e.Start()
+//	if err != nil { ... }
+//	for e.Next() {
+//		rec := e.Record()
+//		...
+//	}
+//	if err := e.Err(); err != nil { ... }
+func (e *Engine) Start() error {
+	e.err = e.init(nil)
+	if e.msg == nil {
+		e.logger = log.New(ioutil.Discard, "", 0)
+	}
+	return e.err
+}
+
+// Next performs the next iteration of a simulation initialized by Start.
+// Next returns false when the simulation is over or when an error occurred,
+// reported by Err.
+func (e *Engine) Next() bool {
+	if e.err != nil {
+		return false
+	}
+	if e.counts == nil {
+		e.err = errors.New("sim: Next called before Start")
+		return false
+	}
+	if e.iter >= e.NumIters {
+		return false
+	}
+	e.err = e.step(1)
+	return e.err == nil
+}
+
+// Err returns the error, if any, encountered by Start or Next.
+func (e *Engine) Err() error {
+	return e.err
+}
+
+// Record returns the state of the population at the current iteration.
+func (e *Engine) Record() Record {
+	return Record{
+		Iter:       e.iter,
+		Counts:     e.Counts(),
+		T9:         e.cond.T9,
+		Ye:         e.Ye(),
+		Size:       len(e.nuclei),
+		Acceptance: e.acc,
+	}
+}
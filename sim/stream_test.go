@@ -0,0 +1,132 @@
+package sim
+
+import (
+	"bytes"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recorder is an Output keeping the records of a run.
+type recorder struct {
+	recs []Record
+}
+
+func (r *recorder) WriteHeader(meta Metadata) error { return nil }
+func (r *recorder) WriteRecord(rec Record) error    { r.recs = append(r.recs, rec); return nil }
+func (r *recorder) WriteFlux(rf ReactionFlux) error { return nil }
+func (r *recorder) Flush() error                    { return nil }
+
+func TestStream(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		e    Engine
+	}{
+		{
+			name: "default",
+			e:    Engine{NumIters: 2000, NumCarbons: 60, Seed: 1234},
+		},
+		{
+			name: "capture-source-sink",
+			e: Engine{
+				NumIters:   2000,
+				NumCarbons: 60,
+				Seed:       1234,
+				Acceptance: true,
+				Conditions: Conditions{T9: 3, Rho: 1e9},
+				Heating:    Heating{HeatCapacity: 1000},
+				Capture:    Capture{Rate: 1},
+				Sources:    []Source{{Every: 100, N: 20}},
+				Sinks:      []Sink{{Every: 250, Fraction: 0.05}},
+			},
+		},
+		{
+			name: "rejection-free",
+			e:    Engine{NumIters: 2000, NumCarbons: 60, Seed: 1234, RejectionFree: true},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var msg bytes.Buffer
+			e := tc.e
+			e.SetLogger(log.New(&msg, "", 0))
+			var out recorder
+			err := e.RunOutput(&out)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s := tc.e
+			err = s.Start()
+			if err != nil {
+				t.Fatal(err)
+			}
+			recs := []Record{s.Record()} // initial population
+			for s.Next() {
+				recs = append(recs, s.Record())
+			}
+			if err := s.Err(); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(recs) != len(out.recs) {
+				t.Fatalf("invalid number of records: got=%d, want=%d", len(recs), len(out.recs))
+			}
+			for i := range recs {
+				if !reflect.DeepEqual(recs[i], out.recs[i]) {
+					t.Fatalf("record %d: got=%+v, want=%+v", i, recs[i], out.recs[i])
+				}
+			}
+			if !reflect.DeepEqual(s.Flux(), e.Flux()) {
+				t.Fatalf("invalid reaction flux:\ngot= %v\nwant=%v", s.Flux(), e.Flux())
+			}
+		})
+	}
+}
+
+func TestStreamLogger(t *testing.T) {
+	var msg bytes.Buffer
+	e := Engine{NumIters: 100, NumCarbons: 60}
+	err := e.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for e.Next() {
+	}
+	if e.msg != nil {
+		t.Fatalf("logger of the engine modified by Start")
+	}
+
+	e.SetLogger(log.New(&msg, "", 0))
+	err = e.RunOutput(&recorder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(msg.String(), "iter #100/100") {
+		t.Fatalf("missing messages of the run:\n%s", msg.String())
+	}
+
+	msg.Reset()
+	err = e.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for e.Next() {
+	}
+	if !strings.Contains(msg.String(), "iter #100/100") {
+		t.Fatalf("missing messages of the stream:\n%s", msg.String())
+	}
+}
+
+func TestNextBeforeStart(t *testing.T) {
+	for _, n := range []int{0, 10} {
+		e := Engine{NumIters: n}
+		if e.Next() {
+			t.Fatalf("NumIters=%d: Next succeeded before Start", n)
+		}
+		err := e.Err()
+		if err == nil || err.Error() != "sim: Next called before Start" {
+			t.Fatalf("NumIters=%d: invalid error: %v", n, err)
+		}
+	}
+}
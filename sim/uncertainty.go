@@ -0,0 +1,131 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Distribution is the probability distribution of an uncertain cross-section.
+type Distribution int
+
+const (
+	Gaussian  Distribution = iota // normal distribution, truncated at zero (negative values are drawn again)
+	LogNormal                     // log-normal distribution
+)
+
+var distributionNames = [...]string{
+	Gaussian:  "gaussian",
+	LogNormal: "lognormal",
+}
+
+// ParseDistribution returns the distribution named s.
+func ParseDistribution(s string) (Distribution, error) {
+	for i, name := range distributionNames {
+		if name == s {
+			return Distribution(i), nil
+		}
+	}
+	return Gaussian, fmt.Errorf("sim: invalid distribution %q", s)
+}
+
+func (d Distribution) String() string {
+	if d < 0 || int(d) >= len(distributionNames) {
+		return fmt.Sprintf("Distribution(%d)", int(d))
+	}
+	return distributionNames[d]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Distribution) MarshalText() ([]byte, error) {
+	if d < 0 || int(d) >= len(distributionNames) {
+		return nil, fmt.Errorf("sim: invalid distribution %d", int(d))
+	}
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Distribution) UnmarshalText(text []byte) error {
+	v, err := ParseDistribution(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// CrossSection is a tabulated cross-section with its uncertainty.
+//
+// Gaussian cross-sections are sampled as Value*(1+Err*x), and log-normal
+// ones as Value*exp(Err*x), with x drawn from the standard normal distribution.
+type CrossSection struct {
+	Value float64
+	Err   float64 // relative uncertainty (Gaussian) or uncertainty of the logarithm (log-normal)
+	Dist  Distribution
+}
+
+// Sample returns a value of the cross-section drawn at random.
+func (xs CrossSection) Sample(rng *rand.Rand) float64 {
+	if xs.Err == 0 {
+		return xs.Value
+	}
+	x := rng.NormFloat64()
+	switch xs.Dist {
+	case LogNormal:
+		return xs.Value * math.Exp(xs.Err*x)
+	default:
+		v := xs.Value * (1 + xs.Err*x)
+		for v < 0 {
+			v = xs.Value * (1 + xs.Err*rng.NormFloat64())
+		}
+		return v
+	}
+}
+
+// Table is a table of cross-sections with uncertainties.
+type Table map[Pair]CrossSection
+
+// NewTable returns the cross-sections of the Standard fusion model,
+// all with the same relative uncertainty err, distributed after d.
+func NewTable(err float64, d Distribution) Table {
+	table := make(Table, len(xsects)/2+1)
+	for p, v := range CrossSections() {
+		table[p] = CrossSection{Value: v, Err: err, Dist: d}
+	}
+	return table
+}
+
+// Sample returns a table of cross-sections drawn at random.
+func (t Table) Sample(rng *rand.Rand) map[Pair]float64 {
+	pairs := make([]Pair, 0, len(t))
+	for p := range t {
+		pairs = append(pairs, p)
+	}
+	// sample in a fixed order for reproducible tables.
+	sortPairs(pairs)
+
+	table := make(map[Pair]float64, len(t))
+	for _, p := range pairs {
+		table[p] = t[p].Sample(rng)
+	}
+	return table
+}
+
+// normalize divides the cross-sections of table by their maximum when it
+// exceeds 1, so that fusion attempts do not clip them, and returns the
+// normalized table with the normalization factor.
+// Simulations using the normalized table have to be stretched by that
+// factor (see stretch).
+func normalize(table map[Pair]float64) (map[Pair]float64, float64) {
+	norm := 1.0
+	for _, xs := range table {
+		norm = math.Max(norm, xs)
+	}
+	if norm == 1 {
+		return table, norm
+	}
+	for p, xs := range table {
+		table[p] = xs / norm
+	}
+	return table, norm
+}
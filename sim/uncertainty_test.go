@@ -0,0 +1,64 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestNormalizeSample(t *testing.T) {
+	for _, d := range []Distribution{Gaussian, LogNormal} {
+		t.Run(d.String(), func(t *testing.T) {
+			tbl := NewTable(0.5, d)
+			rng := rand.New(rand.NewSource(1234))
+			saturated := 0
+			for i := 0; i < 100; i++ {
+				sample := tbl.Sample(rng)
+				raw := make(map[Pair]float64, len(sample))
+				max := 0.0
+				for p, xs := range sample {
+					raw[p] = xs
+					max = math.Max(max, xs)
+				}
+				if max > 1 {
+					saturated++
+				}
+
+				table, norm := normalize(sample)
+				if want := math.Max(1, max); norm != want {
+					t.Fatalf("invalid normalization: got=%v, want=%v", norm, want)
+				}
+				for p, xs := range table {
+					if xs > 1 {
+						t.Fatalf("%v: saturated probability %v", p, xs)
+					}
+					if math.Abs(xs*norm-raw[p]) > 1e-12 {
+						t.Fatalf("%v: invalid normalized probability: got=%v, want=%v", p, xs, raw[p]/norm)
+					}
+				}
+			}
+			if saturated == 0 {
+				t.Fatalf("no sampled table above 1")
+			}
+		})
+	}
+}
+
+func TestSampleTruncated(t *testing.T) {
+	xs := CrossSection{Value: 1, Err: 2, Dist: Gaussian}
+	rng := rand.New(rand.NewSource(1234))
+	const n = 10000
+	var mean float64
+	for i := 0; i < n; i++ {
+		v := xs.Sample(rng)
+		if v <= 0 {
+			// clamping would give zero for about 31% of the values.
+			t.Fatalf("invalid sample of a truncated distribution: %v", v)
+		}
+		mean += v / n
+	}
+	// mean of N(1, 2) truncated at zero: 1 + 2*φ(0.5)/Φ(0.5).
+	if want := 1 + 2*0.3520653/0.6914625; math.Abs(mean-want) > 0.05 {
+		t.Errorf("invalid mean: got=%v, want=%v", mean, want)
+	}
+}
@@ -0,0 +1,114 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+)
+
+// FallbackMode selects how an Engine handles pairs of nuclei
+// missing from the tabulated cross-sections.
+type FallbackMode int
+
+const (
+	FallbackNone    FallbackMode = iota // unlisted pairs never fuse
+	FallbackCoulomb                     // unlisted pairs fuse with a Coulomb barrier estimate
+	FallbackStrict                      // unlisted pairs abort the simulation
+)
+
+var fallbackNames = [...]string{
+	FallbackNone:    "none",
+	FallbackCoulomb: "coulomb",
+	FallbackStrict:  "strict",
+}
+
+// ParseFallbackMode returns the fallback mode named s.
+func ParseFallbackMode(s string) (FallbackMode, error) {
+	for i, name := range fallbackNames {
+		if name == s {
+			return FallbackMode(i), nil
+		}
+	}
+	return FallbackNone, fmt.Errorf("sim: invalid fallback mode %q", s)
+}
+
+func (m FallbackMode) String() string {
+	if m < 0 || int(m) >= len(fallbackNames) {
+		return fmt.Sprintf("FallbackMode(%d)", int(m))
+	}
+	return fallbackNames[m]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (m FallbackMode) MarshalText() ([]byte, error) {
+	if m < 0 || int(m) >= len(fallbackNames) {
+		return nil, fmt.Errorf("sim: invalid fallback mode %d", int(m))
+	}
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *FallbackMode) UnmarshalText(text []byte) error {
+	v, err := ParseFallbackMode(string(text))
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// Fallback describes how an Engine estimates the fusion probability
+// of a pair of nuclei missing from the tabulated cross-sections.
+//
+// The Coulomb estimate suppresses a pair exponentially with the
+// penetration exponent of its Coulomb barrier,
+//
+//	b(n1, n2) = Z1*Z2*sqrt(mu) / (A1^1/3 + A2^1/3)
+//
+// where mu is the reduced mass of the pair, relative to the one of the
+// 12C+12C reference channel:
+//
+//	P = Norm * exp(-Slope * (b(n1, n2)/b(12C, 12C) - 1))
+type Fallback struct {
+	Mode  FallbackMode
+	Norm  float64 // probability of the reference channel (zero: 1)
+	Slope float64 // steepness of the barrier suppression (zero: 1)
+}
+
+// Coulomb returns the fusion probability of the nuclei n1 and n2
+// estimated from their Coulomb barrier.
+func (fb Fallback) Coulomb(n1, n2 Nucleus) float64 {
+	norm := fb.Norm
+	if norm == 0 {
+		norm = 1
+	}
+	slope := fb.Slope
+	if slope == 0 {
+		slope = 1
+	}
+	p := norm * math.Exp(-slope*(barrier(n1, n2)/barrier(nC, nC)-1))
+	return math.Max(0, math.Min(1, p))
+}
+
+// probability returns the fusion probability of the nuclei n1 and n2,
+// for a pair without tabulated cross-section.
+func (fb Fallback) probability(n1, n2 Nucleus) (float64, error) {
+	switch fb.Mode {
+	case FallbackNone:
+		return 0, nil
+	case FallbackCoulomb:
+		return fb.Coulomb(n1, n2), nil
+	case FallbackStrict:
+		return 0, fmt.Errorf("sim: no cross-section for %v + %v", n1, n2)
+	}
+	return 0, fmt.Errorf("sim: invalid fallback mode %d", int(fb.Mode))
+}
+
+// barrier returns the Coulomb barrier penetration exponent of two nuclei,
+// up to a constant factor.
+func barrier(n1, n2 Nucleus) float64 {
+	a1 := float64(n1.A)
+	a2 := float64(n2.A)
+	mu := a1 * a2 / (a1 + a2)
+	r := math.Cbrt(a1) + math.Cbrt(a2)
+	return float64(n1.Z*n2.Z) * math.Sqrt(mu) / r
+}